@@ -0,0 +1,6 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package annotationsync synchronizes an app's annotations with question and answer pairs kept
+// in CSV or JSONL files, and exports the current annotations in the same formats.
+package annotationsync
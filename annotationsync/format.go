@@ -0,0 +1,153 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package annotationsync
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Format is a file format for question and answer pairs.
+type Format string
+
+const (
+	// FormatCSV is a CSV file with a header row containing question and answer columns.
+	FormatCSV Format = "csv"
+	// FormatJSONL is a file with one {"question": ..., "answer": ...} object per line.
+	FormatJSONL Format = "jsonl"
+)
+
+const (
+	columnQuestion = "question"
+	columnAnswer   = "answer"
+	// maxLineSize is the longest JSONL line accepted.
+	maxLineSize = 1 << 20
+)
+
+// Pair is a question and its curated answer.
+type Pair struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// FormatFromPath returns the format matching the extension of name.
+func FormatFromPath(name string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unsupported annotation file %q, expected .csv or .jsonl", name)
+	}
+}
+
+// Read reads question and answer pairs in the given format. Blank lines and rows without a
+// question are skipped.
+func Read(r io.Reader, format Format) ([]Pair, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSONL:
+		return readJSONL(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// Write writes question and answer pairs in the given format.
+func Write(w io.Writer, format Format, pairs []Pair) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{columnQuestion, columnAnswer}); err != nil {
+			return err
+		}
+		for _, p := range pairs {
+			if err := cw.Write([]string{p.Question, p.Answer}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, p := range pairs {
+			if err := enc.Encode(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func readCSV(r io.Reader) ([]Pair, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+	qi, ai := slices.Index(header, columnQuestion), slices.Index(header, columnAnswer)
+	if qi < 0 || ai < 0 {
+		return nil, errors.New("CSV header must contain question and answer columns")
+	}
+
+	var pairs []Pair
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return pairs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if qi >= len(record) || strings.TrimSpace(record[qi]) == "" {
+			continue
+		}
+		p := Pair{Question: record[qi]}
+		if ai < len(record) {
+			p.Answer = record[ai]
+		}
+		pairs = append(pairs, p)
+	}
+}
+
+func readJSONL(r io.Reader) ([]Pair, error) {
+	var pairs []Pair
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var p Pair
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if strings.TrimSpace(p.Question) == "" {
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, scanner.Err()
+}
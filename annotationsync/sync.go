@@ -0,0 +1,179 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package annotationsync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/internal/syncutil"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// Client is the subset of the annotation API used by the sync. It is implemented by *v1.AnnotationService.
+type Client interface {
+	List(ctx context.Context, query schema.AnnotationListQuery) (schema.AnnotationListResponse, error)
+	Create(ctx context.Context, req schema.AnnotationRequest) (schema.Annotation, error)
+	Update(ctx context.Context, annotationID string, req schema.AnnotationRequest) (schema.Annotation, error)
+	Delete(ctx context.Context, annotationID string) error
+}
+
+var _ Client = (*v1.AnnotationService)(nil)
+
+// Action is the change applied to a single annotation.
+type Action = syncutil.Action
+
+// Actions.
+const (
+	ActionCreate = syncutil.ActionCreate
+	ActionUpdate = syncutil.ActionUpdate
+	ActionDelete = syncutil.ActionDelete
+)
+
+// Operation is a planned or applied change to a single annotation.
+type Operation struct {
+	Action Action
+	// ID is the annotation ID; it is empty for creations.
+	ID        string
+	Question  string
+	Answer    string
+	OldAnswer string
+	Err       error
+}
+
+// Describe returns the action, the quoted question and the error of the operation.
+func (op Operation) Describe() (Action, string, error) {
+	return op.Action, strconv.Quote(op.Question), op.Err
+}
+
+// Options configures a sync.
+type Options struct {
+	// Prune deletes annotations whose question is not in the source.
+	Prune bool
+	// DryRun plans the operations without applying them.
+	DryRun bool
+	// Concurrency is the maximum number of concurrent write requests. Default: 4
+	Concurrency int
+}
+
+// Report summarizes a sync.
+type Report = syncutil.Report[Operation]
+
+// FetchAll retrieves every annotation of the app, following pagination.
+func FetchAll(ctx context.Context, client Client) ([]schema.Annotation, error) {
+	return syncutil.FetchAll(ctx, func(ctx context.Context, page, limit int) ([]schema.Annotation, bool, error) {
+		resp, err := client.List(ctx, schema.AnnotationListQuery{Page: page, Limit: limit})
+		return resp.Data, resp.HasMore, err
+	})
+}
+
+// Export writes every annotation of the app in the given format.
+func Export(ctx context.Context, client Client, w io.Writer, format Format) error {
+	annotations, err := FetchAll(ctx, client)
+	if err != nil {
+		return err
+	}
+	pairs := make([]Pair, 0, len(annotations))
+	for _, a := range annotations {
+		pairs = append(pairs, Pair{Question: a.Question, Answer: a.Answer})
+	}
+	return Write(w, format, pairs)
+}
+
+// Plan computes the operations that make existing match pairs. Annotations are matched by question,
+// ignoring surrounding whitespace. Duplicate questions in pairs are rejected.
+func Plan(existing []schema.Annotation, pairs []Pair, prune bool) ([]Operation, int, error) {
+	current := make(map[string]schema.Annotation, len(existing))
+	var ops []Operation
+	for _, a := range existing {
+		key := questionKey(a.Question)
+		if _, ok := current[key]; ok {
+			// Only the first annotation of a question is kept in sync; the others are duplicates.
+			if prune {
+				ops = append(ops, Operation{Action: ActionDelete, ID: a.ID, Question: a.Question, OldAnswer: a.Answer})
+			}
+			continue
+		}
+		current[key] = a
+	}
+
+	var unchanged int
+	wanted := make(map[string]bool, len(pairs))
+	for _, p := range pairs {
+		key := questionKey(p.Question)
+		if wanted[key] {
+			return nil, 0, fmt.Errorf("duplicate question %q", p.Question)
+		}
+		wanted[key] = true
+
+		a, ok := current[key]
+		switch {
+		case !ok:
+			ops = append(ops, Operation{Action: ActionCreate, Question: p.Question, Answer: p.Answer})
+		case a.Answer != p.Answer || a.Question != p.Question:
+			ops = append(ops, Operation{Action: ActionUpdate, ID: a.ID, Question: p.Question, Answer: p.Answer, OldAnswer: a.Answer})
+		default:
+			unchanged++
+		}
+	}
+
+	if prune {
+		for _, a := range existing {
+			key := questionKey(a.Question)
+			if !wanted[key] && current[key].ID == a.ID {
+				ops = append(ops, Operation{Action: ActionDelete, ID: a.ID, Question: a.Question, OldAnswer: a.Answer})
+			}
+		}
+	}
+	return ops, unchanged, nil
+}
+
+// Sync makes the app's annotations match pairs and reports every operation. Operations are applied
+// with bounded concurrency; failed operations are recorded in the report and returned joined.
+func Sync(ctx context.Context, client Client, pairs []Pair, opts Options) (*Report, error) {
+	existing, err := FetchAll(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	ops, unchanged, err := Plan(existing, pairs, opts.Prune)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{DryRun: opts.DryRun, Operations: ops, Unchanged: unchanged}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	errs := syncutil.Each(ctx, len(ops), opts.Concurrency, func(ctx context.Context, i int) error {
+		return apply(ctx, client, ops[i])
+	})
+	for i, err := range errs {
+		report.Operations[i].Err = err
+	}
+	return report, report.Err()
+}
+
+func apply(ctx context.Context, client Client, op Operation) error {
+	req := schema.AnnotationRequest{Question: op.Question, Answer: op.Answer}
+	switch op.Action {
+	case ActionCreate:
+		_, err := client.Create(ctx, req)
+		return err
+	case ActionUpdate:
+		_, err := client.Update(ctx, op.ID, req)
+		return err
+	case ActionDelete:
+		return client.Delete(ctx, op.ID)
+	default:
+		return fmt.Errorf("unknown action %q", op.Action)
+	}
+}
+
+func questionKey(question string) string {
+	return strings.TrimSpace(question)
+}
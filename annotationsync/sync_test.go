@@ -0,0 +1,112 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package annotationsync
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+type fakeClient struct {
+	mu          sync.Mutex
+	annotations []schema.Annotation
+	nextID      int
+}
+
+func (f *fakeClient) List(_ context.Context, q schema.AnnotationListQuery) (schema.AnnotationListResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, hasMore := paginate(f.annotations, q.Page, q.Limit)
+	return schema.AnnotationListResponse{Data: data, HasMore: hasMore}, nil
+}
+
+func (f *fakeClient) Create(_ context.Context, req schema.AnnotationRequest) (schema.Annotation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	a := schema.Annotation{ID: "new-" + strconv.Itoa(f.nextID), Question: req.Question, Answer: req.Answer}
+	f.annotations = append(f.annotations, a)
+	return a, nil
+}
+
+func (f *fakeClient) Update(_ context.Context, id string, req schema.AnnotationRequest) (schema.Annotation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, a := range f.annotations {
+		if a.ID == id {
+			f.annotations[i].Question, f.annotations[i].Answer = req.Question, req.Answer
+		}
+	}
+	return schema.Annotation{ID: id}, nil
+}
+
+func (f *fakeClient) Delete(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, a := range f.annotations {
+		if a.ID == id {
+			f.annotations = append(f.annotations[:i], f.annotations[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	source := "question,answer\nWhat is dify?,An LLM app platform\nHow to sync?,Use annotationsync\n"
+	pairs, err := Read(strings.NewReader(source), FormatCSV)
+	require.NoError(t, err)
+
+	client := &fakeClient{annotations: []schema.Annotation{
+		{ID: "1", Question: "What is dify?", Answer: "A platform"},
+		{ID: "2", Question: "Obsolete?", Answer: "Yes"},
+	}}
+
+	report, err := Sync(t.Context(), client, pairs, Options{Prune: true, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Count(ActionCreate))
+	assert.Equal(t, 1, report.Count(ActionUpdate))
+	assert.Equal(t, 1, report.Count(ActionDelete))
+	assert.Len(t, client.annotations, 2)
+
+	report, err = Sync(t.Context(), client, pairs, Options{Prune: true})
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed())
+
+	var out bytes.Buffer
+	require.NoError(t, Export(t.Context(), client, &out, FormatJSONL))
+	assert.Equal(t, `{"question":"What is dify?","answer":"An LLM app platform"}
+{"question":"How to sync?","answer":"Use annotationsync"}
+`, out.String())
+
+	report, err = Sync(t.Context(), client, pairs, Options{Prune: true})
+	require.NoError(t, err)
+	assert.Empty(t, report.Operations)
+	assert.Equal(t, 2, report.Unchanged)
+}
+
+func TestPlanRejectsDuplicateQuestions(t *testing.T) {
+	_, _, err := Plan(nil, []Pair{{Question: "q"}, {Question: " q "}}, false)
+	assert.EqualError(t, err, `duplicate question " q "`)
+}
+
+// paginate returns the page of items selected by page and limit, as a list endpoint does, and
+// whether more pages follow.
+func paginate[T any](items []T, page, limit int) ([]T, bool) {
+	if page < 1 || limit < 1 {
+		return nil, false
+	}
+	start := min((page-1)*limit, len(items))
+	end := min(start+limit, len(items))
+	return items[start:end], end < len(items)
+}
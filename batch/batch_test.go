@@ -0,0 +1,145 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+type fakeRunner struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeRunner) Run(_ context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error) {
+	var inputs map[string]string
+	if err := json.Unmarshal(req.Inputs, &inputs); err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
+	f.mu.Lock()
+	f.calls[inputs["text"]]++
+	calls := f.calls[inputs["text"]]
+	f.mu.Unlock()
+
+	switch inputs["text"] {
+	case "flaky":
+		if calls == 1 {
+			return schema.RunWorkflowResponse{}, &v1.APIError{StatusCode: http.StatusBadGateway, Code: "bad_gateway"}
+		}
+	case "offline":
+		if calls == 1 {
+			return schema.RunWorkflowResponse{}, &url.Error{Op: "Post", URL: "http://dify", Err: errors.New("connection refused")}
+		}
+	case "invalid":
+		return schema.RunWorkflowResponse{}, &v1.APIError{StatusCode: http.StatusBadRequest, Code: "invalid_param"}
+	case "malformed":
+		return schema.RunWorkflowResponse{}, &v1.ValidationError{Fields: []v1.FieldError{{Variable: "text", Message: "is malformed"}}}
+	}
+	return schema.RunWorkflowResponse{
+		WorkflowRunID: "run-" + inputs["text"],
+		Data: schema.RunWorkflowResponseData{
+			Status:     schema.WorkflowStatusSucceeded,
+			Outputs:    map[string]any{"upper": strings.ToUpper(inputs["text"])},
+			TotalToken: 10,
+		},
+	}, nil
+}
+
+func TestRun(t *testing.T) {
+	input := "id,text\na,hello\nb,flaky\nc,invalid\nd,offline\ne,malformed\n"
+	checkpointFile := filepath.Join(t.TempDir(), "batch.checkpoint")
+	runner := &fakeRunner{calls: map[string]int{}}
+	opts := Options{Workers: 2, MinBackoff: time.Millisecond, RateLimit: 1000, Burst: 2}
+
+	checkpoint, err := OpenCheckpoint(checkpointFile)
+	require.NoError(t, err)
+	opts.Checkpoint = checkpoint
+	var out bytes.Buffer
+	progress, err := Run(t.Context(), runner, Items(strings.NewReader(input), FormatCSV, "id"), NewJSONLSink(&out), opts)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.Close())
+	assert.Equal(t, Progress{Finished: 5, Succeeded: 3, Failed: 2, Tokens: 30}, Progress{
+		Finished: progress.Finished, Succeeded: progress.Succeeded, Failed: progress.Failed, Tokens: progress.Tokens,
+	})
+	assert.Equal(t, map[string]int{"hello": 1, "flaky": 2, "invalid": 1, "offline": 2, "malformed": 1}, runner.calls)
+
+	results := map[string]Result{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r Result
+		require.NoError(t, json.Unmarshal([]byte(line), &r))
+		results[r.ID] = r
+	}
+	assert.Equal(t, "HELLO", results["a"].Outputs["upper"])
+	assert.Equal(t, 2, results["b"].Attempts)
+	assert.Equal(t, StatusError, results["c"].Status)
+	assert.Equal(t, 2, results["d"].Attempts)
+	assert.Equal(t, StatusError, results["e"].Status)
+
+	// Resuming skips the finished items and only runs the new one.
+	checkpoint, err = OpenCheckpoint(checkpointFile)
+	require.NoError(t, err)
+	defer checkpoint.Close()
+	opts.Checkpoint = checkpoint
+	input += "f,world\n"
+	out.Reset()
+	progress, err = Run(t.Context(), runner, Items(strings.NewReader(input), FormatCSV, "id"), NewCSVAppendSink(&out), opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, progress.Finished)
+	assert.Equal(t, 5, progress.Skipped)
+	assert.True(t, strings.HasPrefix(out.String(), "f,run-world,succeeded,10,"))
+}
+
+func TestRunSourceError(t *testing.T) {
+	input := `{"text": "hello"}` + "\n" + `{"text": ` + "\n"
+	var out bytes.Buffer
+	progress, err := Run(t.Context(), &fakeRunner{calls: map[string]int{}}, Items(strings.NewReader(input), FormatJSONL, ""), NewJSONLSink(&out), Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+	assert.LessOrEqual(t, progress.Finished, 1)
+}
+
+// blockingRunner blocks every run until ctx is done.
+type blockingRunner struct {
+	started chan struct{}
+}
+
+func (r blockingRunner) Run(ctx context.Context, _ schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error) {
+	select {
+	case r.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return schema.RunWorkflowResponse{}, ctx.Err()
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	runner := blockingRunner{started: make(chan struct{}, 1)}
+	go func() {
+		<-runner.started
+		cancel()
+	}()
+
+	var out bytes.Buffer
+	progress, err := Run(ctx, runner, Items(strings.NewReader("id,text\na,hello\nb,world\n"), FormatCSV, "id"), NewJSONLSink(&out), Options{Workers: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, progress.Finished)
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,88 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// checkpointPerm is the permission of new checkpoint files.
+const checkpointPerm = 0o644
+
+// Checkpoint is a file listing the IDs of the finished items, one per line. Items are appended once
+// their result is written, so a batch resumed with the same checkpoint skips them.
+type Checkpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[string]bool
+}
+
+// OpenCheckpoint opens or creates a checkpoint file and loads the finished items.
+func OpenCheckpoint(name string) (*Checkpoint, error) {
+	done := make(map[string]bool)
+	data, err := os.ReadFile(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	lines := strings.Split(string(data), "\n")
+	// A line without a trailing newline was cut short by a crash and is ignored.
+	for _, id := range lines[:len(lines)-1] {
+		if id != "" {
+			done[id] = true
+		}
+	}
+
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, checkpointPerm)
+	if err != nil {
+		return nil, err
+	}
+	// Truncate a partial last line so that the next ID starts on its own line.
+	if err := file.Truncate(int64(len(data) - len(lines[len(lines)-1]))); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Checkpoint{file: file, done: done}, nil
+}
+
+// Done reports whether the item finished in a previous run.
+func (c *Checkpoint) Done(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done[id]
+}
+
+// Len returns the number of finished items.
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.done)
+}
+
+// Add records a finished item and syncs the file.
+func (c *Checkpoint) Add(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if strings.ContainsAny(id, "\r\n") {
+		return errors.New("item IDs cannot contain line breaks")
+	}
+	if _, err := c.file.WriteString(id + "\n"); err != nil {
+		return err
+	}
+	c.done[id] = true
+	return c.file.Sync()
+}
+
+// Close closes the checkpoint file.
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
@@ -0,0 +1,39 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "batch.checkpoint")
+	// The last line was cut short by a crash.
+	require.NoError(t, os.WriteFile(name, []byte("a\n\nb\npart"), 0o644))
+
+	checkpoint, err := OpenCheckpoint(name)
+	require.NoError(t, err)
+	assert.Equal(t, 2, checkpoint.Len())
+	assert.True(t, checkpoint.Done("a"))
+	assert.True(t, checkpoint.Done("b"))
+	assert.False(t, checkpoint.Done("part"))
+
+	require.NoError(t, checkpoint.Add("c"))
+	assert.Error(t, checkpoint.Add("d\ne"))
+	require.NoError(t, checkpoint.Close())
+
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "a\n\nb\nc\n", string(data))
+
+	checkpoint, err = OpenCheckpoint(name)
+	require.NoError(t, err)
+	defer checkpoint.Close()
+	assert.Equal(t, 3, checkpoint.Len())
+}
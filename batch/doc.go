@@ -0,0 +1,8 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package batch runs a workflow once per item of a CSV or JSONL input with a pool of workers.
+// Failed runs are retried, the request rate can be limited, and results are written to a JSONL
+// or CSV sink. A checkpoint file records the finished items so that an interrupted batch resumes
+// where it stopped.
+package batch
@@ -0,0 +1,66 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket allowing rate events per second with bursts of up to burst events.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+}
+
+// newLimiter returns a limiter, or nil when rate is not positive, which never waits.
+func newLimiter(rate float64, burst int) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	burst = max(burst, 1)
+	return &limiter{
+		interval: time.Duration(float64(time.Second) / rate),
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until an event is allowed or ctx is done.
+func (l *limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(float64(l.burst), l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	l.last = now
+	// Take the token now, even if it is not available yet, so that waiters are served in order.
+	l.tokens--
+	wait := time.Duration(0)
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens * float64(l.interval))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	var unlimited *limiter
+	assert.Nil(t, newLimiter(0, 5))
+	require.NoError(t, unlimited.Wait(t.Context()))
+
+	l := newLimiter(50, 2)
+	start := time.Now()
+	for range 4 {
+		require.NoError(t, l.Wait(t.Context()))
+	}
+	// The burst passes at once and the two other events wait 20ms each.
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+
+	// A canceled wait gives its token back.
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	assert.Greater(t, tokens, -1.0)
+}
@@ -0,0 +1,287 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+const (
+	// StatusSucceeded is the status of items whose run succeeded.
+	StatusSucceeded = schema.WorkflowStatusSucceeded
+	// StatusError is the status of items that could not be run.
+	StatusError = "error"
+
+	// defaultUser is the end user of the runs when Options.User is unset.
+	defaultUser = "batch"
+	// defaultWorkers is the number of workers when Options.Workers is unset.
+	defaultWorkers = 4
+	// defaultMaxAttempts is the number of attempts per item when Options.MaxAttempts is unset.
+	defaultMaxAttempts = 3
+	// defaultMinBackoff is the delay before the first retry when Options.MinBackoff is unset.
+	defaultMinBackoff = time.Second
+	// defaultMaxBackoff bounds the delay between retries when Options.MaxBackoff is unset.
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// Runner executes workflows. It is implemented by *v1.WorkflowService.
+type Runner interface {
+	Run(ctx context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error)
+}
+
+var _ Runner = (*v1.WorkflowService)(nil)
+
+// Options configures a batch.
+type Options struct {
+	// User is the end user sent with every run. Default: batch
+	User string
+	// Workers is the number of concurrent runs. Default: 4
+	Workers int
+	// MaxAttempts is the number of attempts per item. Requests failing with a network error,
+	// HTTP 429 or a server error are retried. Default: 3
+	MaxAttempts int
+	// RetryFailedRuns also retries runs that finished with a failed status.
+	RetryFailedRuns bool
+	// MinBackoff is the delay before the first retry; it doubles on every retry up to MaxBackoff.
+	// Default: 1 second and 30 seconds
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// RateLimit is the maximum number of runs started per second, including retries. Zero is unlimited.
+	RateLimit float64
+	// Burst is the number of runs that may start at once within the rate limit. Default: 1
+	Burst int
+	// Checkpoint skips the items finished in a previous run and records the finished items.
+	Checkpoint *Checkpoint
+	// OnProgress is called after every finished item.
+	OnProgress func(Progress)
+}
+
+// Progress summarizes the progress of a batch.
+type Progress struct {
+	Finished  int
+	Succeeded int
+	Failed    int
+	// Skipped counts the items finished in a previous run.
+	Skipped int
+	Tokens  int
+	Elapsed time.Duration
+}
+
+// Rate returns the number of items finished per second.
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Finished) / p.Elapsed.Seconds()
+}
+
+// Run runs the workflow for every item and writes the results to sink, in completion order.
+// When ctx is done, the items in flight are abandoned without being recorded, so a resumed batch
+// runs them again. Run returns the final progress along with the first error of the source,
+// the sink, the checkpoint or ctx; failed items are only reported in their results.
+func Run(ctx context.Context, runner Runner, items iter.Seq2[Item, error], sink Sink, opts Options) (Progress, error) {
+	b := newBatch(runner, opts)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan Item)
+	results := make(chan Result)
+	var sourceErr error
+	var skipped atomic.Int64
+	go func() {
+		defer close(jobs)
+		for item, err := range items {
+			if err != nil {
+				sourceErr = err
+				cancel()
+				return
+			}
+			if opts.Checkpoint != nil && opts.Checkpoint.Done(item.ID) {
+				skipped.Add(1)
+				continue
+			}
+			select {
+			case jobs <- item:
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range b.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				result := b.run(runCtx, item)
+				if runCtx.Err() != nil {
+					// The item was interrupted; leave it to a resumed batch.
+					continue
+				}
+				results <- result
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	start := time.Now()
+	var progress Progress
+	var err error
+	for result := range results {
+		if err != nil {
+			continue
+		}
+		if err = b.record(sink, result); err != nil {
+			cancel()
+			continue
+		}
+		progress.Finished++
+		progress.Tokens += result.Tokens
+		if result.Succeeded() {
+			progress.Succeeded++
+		} else {
+			progress.Failed++
+		}
+		progress.Skipped = int(skipped.Load())
+		progress.Elapsed = time.Since(start)
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+	progress.Skipped = int(skipped.Load())
+	progress.Elapsed = time.Since(start)
+
+	if flushErr := sink.Flush(); err == nil {
+		err = flushErr
+	}
+	switch {
+	case err != nil:
+		return progress, err
+	case sourceErr != nil:
+		// sourceErr is set before jobs is closed, so it is visible once every worker returned.
+		return progress, sourceErr
+	default:
+		return progress, ctx.Err()
+	}
+}
+
+type batch struct {
+	runner      Runner
+	opts        Options
+	user        string
+	workers     int
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	limiter     *limiter
+}
+
+func newBatch(runner Runner, opts Options) *batch {
+	b := &batch{
+		runner:      runner,
+		opts:        opts,
+		user:        opts.User,
+		workers:     opts.Workers,
+		maxAttempts: opts.MaxAttempts,
+		minBackoff:  opts.MinBackoff,
+		maxBackoff:  opts.MaxBackoff,
+		limiter:     newLimiter(opts.RateLimit, opts.Burst),
+	}
+	if b.user == "" {
+		b.user = defaultUser
+	}
+	if b.workers <= 0 {
+		b.workers = defaultWorkers
+	}
+	if b.maxAttempts <= 0 {
+		b.maxAttempts = defaultMaxAttempts
+	}
+	if b.minBackoff <= 0 {
+		b.minBackoff = defaultMinBackoff
+	}
+	if b.maxBackoff < b.minBackoff {
+		b.maxBackoff = max(defaultMaxBackoff, b.minBackoff)
+	}
+	return b
+}
+
+// run runs the workflow for an item, retrying as configured.
+func (b *batch) run(ctx context.Context, item Item) Result {
+	result := Result{ID: item.ID}
+	inputs, err := json.Marshal(item.Inputs)
+	if err != nil {
+		result.Status, result.Error = StatusError, err.Error()
+		return result
+	}
+	req := schema.RunWorkflowRequest{Inputs: inputs, ResponseMode: v1.BlockingMode, User: b.user}
+
+	start := time.Now()
+	backoff := b.minBackoff
+	for {
+		result = Result{ID: item.ID, Attempts: result.Attempts + 1}
+		retry := false
+		if err = b.limiter.Wait(ctx); err == nil {
+			var resp schema.RunWorkflowResponse
+			resp, err = b.runner.Run(ctx, req)
+			if err == nil {
+				result.RunID, result.Status, result.Tokens = resp.WorkflowRunID, resp.Data.Status, resp.Data.TotalToken
+				result.Outputs, result.Error = resp.Data.Outputs, resp.Data.Error
+				retry = b.opts.RetryFailedRuns && resp.Data.Status != StatusSucceeded
+			} else {
+				result.Status, result.Error = StatusError, err.Error()
+				retry = ctx.Err() == nil && v1.IsTransient(err)
+			}
+		} else {
+			result.Status, result.Error = StatusError, err.Error()
+		}
+		if !retry || result.Attempts >= b.maxAttempts || !sleep(ctx, backoff) {
+			break
+		}
+		backoff = min(backoff*2, b.maxBackoff)
+	}
+	result.Elapsed = time.Since(start)
+	if result.Status == StatusSucceeded {
+		result.Error = ""
+	}
+	return result
+}
+
+// record writes a result and checkpoints its item.
+func (b *batch) record(sink Sink, result Result) error {
+	if err := sink.Write(result); err != nil {
+		return err
+	}
+	if b.opts.Checkpoint == nil {
+		return nil
+	}
+	// The checkpoint must not get ahead of the results, so buffered results are flushed first.
+	if err := sink.Flush(); err != nil {
+		return err
+	}
+	return b.opts.Checkpoint.Add(result.ID)
+}
+
+// sleep waits for d, reporting false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Result is the outcome of an item.
+type Result struct {
+	ID     string `json:"id"`
+	RunID  string `json:"run_id,omitempty"`
+	Status string `json:"status"`
+	// Outputs holds the workflow outputs of the last run, which may have failed.
+	Outputs map[string]any `json:"outputs,omitempty"`
+	Tokens  int            `json:"tokens"`
+	// Elapsed is encoded in milliseconds as elapsed_ms, like the column of CSV results.
+	Elapsed  time.Duration `json:"-"`
+	Attempts int           `json:"attempts"`
+	// Error is the last error of a failed item.
+	Error string `json:"error,omitempty"`
+}
+
+// jsonResult is the JSON encoding of a Result.
+type jsonResult struct {
+	result
+	ElapsedMS int64 `json:"elapsed_ms"`
+}
+
+// result has the fields of Result without its methods.
+type result Result
+
+// MarshalJSON implements json.Marshaler.
+func (r Result) MarshalJSON() ([]byte, error) {
+	return marshal(jsonResult{result: result(r), ElapsedMS: r.Elapsed.Milliseconds()})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var v jsonResult
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*r = Result(v.result)
+	r.Elapsed = time.Duration(v.ElapsedMS) * time.Millisecond
+	return nil
+}
+
+// Succeeded reports whether the workflow run of the item succeeded.
+func (r Result) Succeeded() bool {
+	return r.Error == "" && r.Status == StatusSucceeded
+}
+
+// Sink receives the results of a batch. Write is never called concurrently.
+type Sink interface {
+	Write(result Result) error
+	// Flush writes buffered results to the underlying writer.
+	Flush() error
+}
+
+// NewSink returns a sink writing results in the given format.
+func NewSink(w io.Writer, format Format) (Sink, error) {
+	switch format {
+	case FormatJSONL:
+		return NewJSONLSink(w), nil
+	case FormatCSV:
+		return NewCSVSink(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// JSONLSink writes one JSON result per line.
+type JSONLSink struct {
+	enc *json.Encoder
+}
+
+// NewJSONLSink returns a sink writing JSON lines to w.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONLSink{enc: enc}
+}
+
+// Write writes a result.
+func (s *JSONLSink) Write(result Result) error {
+	return s.enc.Encode(result)
+}
+
+// Flush does nothing; results are written immediately.
+func (s *JSONLSink) Flush() error {
+	return nil
+}
+
+// csvHeader lists the columns written by CSVSink.
+var csvHeader = []string{"id", "run_id", "status", "tokens", "elapsed_ms", "attempts", "error", "outputs"}
+
+// CSVSink writes results as CSV rows, with the outputs as a JSON object in the last column.
+// The header row is written with the first result, so a sink can append to the results of an
+// interrupted batch by starting with header set to false, see NewCSVAppendSink.
+type CSVSink struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVSink returns a sink writing CSV rows to w, starting with a header row.
+func NewCSVSink(w io.Writer) *CSVSink {
+	return &CSVSink{w: csv.NewWriter(w), header: true}
+}
+
+// NewCSVAppendSink returns a sink writing CSV rows to w without a header row.
+func NewCSVAppendSink(w io.Writer) *CSVSink {
+	return &CSVSink{w: csv.NewWriter(w)}
+}
+
+// Write writes a result.
+func (s *CSVSink) Write(result Result) error {
+	if s.header {
+		s.header = false
+		if err := s.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	outputs := ""
+	if result.Outputs != nil {
+		data, err := marshal(result.Outputs)
+		if err != nil {
+			return err
+		}
+		outputs = string(data)
+	}
+	return s.w.Write([]string{
+		result.ID,
+		result.RunID,
+		result.Status,
+		strconv.Itoa(result.Tokens),
+		strconv.FormatInt(result.Elapsed.Milliseconds(), 10),
+		strconv.Itoa(result.Attempts),
+		result.Error,
+		outputs,
+	})
+}
+
+// Flush writes the buffered rows.
+func (s *CSVSink) Flush() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// marshal encodes v as JSON without escaping HTML characters, which are common in workflow outputs.
+func marshal(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,56 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinks(t *testing.T) {
+	results := []Result{
+		{ID: "a", RunID: "r1", Status: StatusSucceeded, Outputs: map[string]any{"text": "<b>"}, Tokens: 3, Elapsed: 1500 * time.Millisecond, Attempts: 1},
+		{ID: "b", Status: StatusError, Elapsed: 20 * time.Millisecond, Attempts: 3, Error: "dify: HTTP 502"},
+	}
+
+	var out bytes.Buffer
+	sink, err := NewSink(&out, FormatJSONL)
+	require.NoError(t, err)
+	for _, r := range results {
+		require.NoError(t, sink.Write(r))
+	}
+	require.NoError(t, sink.Flush())
+	assert.Equal(t, `{"id":"a","run_id":"r1","status":"succeeded","outputs":{"text":"<b>"},"tokens":3,"attempts":1,"elapsed_ms":1500}
+{"id":"b","status":"error","tokens":0,"attempts":3,"error":"dify: HTTP 502","elapsed_ms":20}
+`, out.String())
+	var decoded Result
+	require.NoError(t, json.Unmarshal(bytes.Split(out.Bytes(), []byte("\n"))[0], &decoded))
+	assert.Equal(t, results[0], decoded)
+
+	out.Reset()
+	sink, err = NewSink(&out, FormatCSV)
+	require.NoError(t, err)
+	for _, r := range results {
+		require.NoError(t, sink.Write(r))
+	}
+	require.NoError(t, sink.Flush())
+	assert.Equal(t, `id,run_id,status,tokens,elapsed_ms,attempts,error,outputs
+a,r1,succeeded,3,1500,1,,"{""text"":""<b>""}"
+b,,error,0,20,3,dify: HTTP 502,
+`, out.String())
+
+	out.Reset()
+	sink = NewCSVAppendSink(&out)
+	require.NoError(t, sink.Write(results[1]))
+	require.NoError(t, sink.Flush())
+	assert.Equal(t, "b,,error,0,20,3,dify: HTTP 502,\n", out.String())
+
+	_, err = NewSink(&out, "xml")
+	assert.Error(t, err)
+}
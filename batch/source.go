@@ -0,0 +1,137 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Format is a file format for inputs and results.
+type Format string
+
+const (
+	// FormatCSV is a CSV file with a header row naming the columns.
+	FormatCSV Format = "csv"
+	// FormatJSONL is a file with one JSON object per line.
+	FormatJSONL Format = "jsonl"
+)
+
+// maxLineSize is the longest JSONL line accepted.
+const maxLineSize = 1 << 20
+
+// Item is an input of the batch.
+type Item struct {
+	// ID identifies the item in results and checkpoints.
+	ID     string
+	Inputs map[string]any
+}
+
+// FormatFromPath returns the format matching the extension of name.
+func FormatFromPath(name string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unsupported batch file %q, expected .csv or .jsonl", name)
+	}
+}
+
+// Items reads the items of an input file. CSV columns and JSONL keys are the workflow inputs, except
+// idField which is used as the item ID. Items without an ID are numbered by their line or row,
+// starting at 1, so an input file must not be reordered between a run and its resumption.
+func Items(r io.Reader, format Format, idField string) iter.Seq2[Item, error] {
+	return func(yield func(Item, error) bool) {
+		var err error
+		switch format {
+		case FormatCSV:
+			err = readCSV(r, idField, yield)
+		case FormatJSONL:
+			err = readJSONL(r, idField, yield)
+		default:
+			err = fmt.Errorf("unsupported format %q", format)
+		}
+		if err != nil {
+			yield(Item{}, err)
+		}
+	}
+}
+
+// newItem builds an item, taking its ID from the inputs when idField is set.
+func newItem(n int, inputs map[string]any, idField string) Item {
+	item := Item{ID: strconv.Itoa(n), Inputs: inputs}
+	if idField == "" {
+		return item
+	}
+	if id, ok := inputs[idField]; ok {
+		delete(inputs, idField)
+		if s := fmt.Sprint(id); s != "" {
+			item.ID = s
+		}
+	}
+	return item
+}
+
+func readCSV(r io.Reader, idField string, yield func(Item, error) bool) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		inputs := make(map[string]any, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				inputs[header[i]] = value
+			}
+		}
+		if !yield(newItem(row, inputs, idField), nil) {
+			return nil
+		}
+	}
+}
+
+func readJSONL(r io.Reader, idField string, yield func(Item, error) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var inputs map[string]any
+		if err := json.Unmarshal(data, &inputs); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if !yield(newItem(line, inputs, idField), nil) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,52 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItems(t *testing.T) {
+	collect := func(input string, format Format) ([]Item, error) {
+		var items []Item
+		for item, err := range Items(strings.NewReader(input), format, "id") {
+			if err != nil {
+				return items, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	items, err := collect("{\"id\": \"x\", \"text\": \"a\"}\n\n{\"text\": \"b\"}\n", FormatJSONL)
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{ID: "x", Inputs: map[string]any{"text": "a"}},
+		{ID: "3", Inputs: map[string]any{"text": "b"}},
+	}, items)
+
+	items, err = collect("{\"text\": \"a\"}\n[1, 2]\n{\"text\": \"c\"}\n", FormatJSONL)
+	assert.ErrorContains(t, err, "line 2")
+	assert.Len(t, items, 1)
+
+	_, err = collect(`{"text": "`+strings.Repeat("a", maxLineSize)+`"}`, FormatJSONL)
+	assert.Error(t, err)
+
+	items, err = collect("\ufeffid, text\n,a\ny,b\n", FormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{ID: "1", Inputs: map[string]any{"text": "a"}},
+		{ID: "y", Inputs: map[string]any{"text": "b"}},
+	}, items)
+
+	_, err = collect("id,text\n\"open,a\n", FormatCSV)
+	assert.Error(t, err)
+
+	_, err = collect("", "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/yeeaiclub/dify-go/internal/handler"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// defaultJobPollInterval is the default interval between two job status requests.
+const defaultJobPollInterval = time.Second
+
+// AnnotationService is a service for managing the annotations of an app.
+type AnnotationService struct {
+	*BaseClient
+}
+
+// NewAnnotationService creates a new AnnotationService instance with the provided baseURL and apiKey.
+func NewAnnotationService(baseURL, apiKey string) *AnnotationService {
+	baseClient := &BaseClient{
+		client:  handler.NewClient(),
+		apiKey:  apiKey,
+		baseURL: baseURL,
+	}
+	return &AnnotationService{baseClient}
+}
+
+// List retrieves a page of annotations, optionally filtered by keyword.
+func (a *AnnotationService) List(
+	ctx context.Context,
+	query schema.AnnotationListQuery,
+) (schema.AnnotationListResponse, error) {
+	var respData schema.AnnotationListResponse
+	err := a.send(ctx, http.MethodGet, "v1/apps/annotations", nil, query, &respData)
+	if err != nil {
+		return schema.AnnotationListResponse{}, err
+	}
+	return respData, nil
+}
+
+// Create creates an annotation.
+func (a *AnnotationService) Create(ctx context.Context, req schema.AnnotationRequest) (schema.Annotation, error) {
+	var respData schema.Annotation
+	err := a.send(ctx, http.MethodPost, "v1/apps/annotations", req, nil, &respData)
+	if err != nil {
+		return schema.Annotation{}, err
+	}
+	return respData, nil
+}
+
+// Update updates the question and answer of an annotation.
+func (a *AnnotationService) Update(
+	ctx context.Context,
+	annotationID string,
+	req schema.AnnotationRequest,
+) (schema.Annotation, error) {
+	var respData schema.Annotation
+	err := a.send(ctx, http.MethodPut, "v1/apps/annotations/"+annotationID, req, nil, &respData)
+	if err != nil {
+		return schema.Annotation{}, err
+	}
+	return respData, nil
+}
+
+// Delete deletes an annotation.
+func (a *AnnotationService) Delete(ctx context.Context, annotationID string) error {
+	return a.send(ctx, http.MethodDelete, "v1/apps/annotations/"+annotationID, nil, nil, nil)
+}
+
+// EnableReply enables annotation reply with the given embedding model and score threshold.
+// The settings are applied asynchronously; use WaitForReplyJob to wait for the returned job.
+func (a *AnnotationService) EnableReply(
+	ctx context.Context,
+	req schema.AnnotationReplyRequest,
+) (schema.AnnotationReplyJob, error) {
+	return a.setReply(ctx, schema.AnnotationReplyEnable, req)
+}
+
+// DisableReply disables annotation reply. Dify expects the current embedding settings in the request.
+// The settings are applied asynchronously; use WaitForReplyJob to wait for the returned job.
+func (a *AnnotationService) DisableReply(
+	ctx context.Context,
+	req schema.AnnotationReplyRequest,
+) (schema.AnnotationReplyJob, error) {
+	return a.setReply(ctx, schema.AnnotationReplyDisable, req)
+}
+
+// GetReplyJobStatus retrieves the status of an annotation reply job started with the given action.
+func (a *AnnotationService) GetReplyJobStatus(
+	ctx context.Context,
+	action, jobID string,
+) (schema.AnnotationReplyJob, error) {
+	var respData schema.AnnotationReplyJob
+	err := a.send(ctx, http.MethodGet, "v1/apps/annotation-reply/"+action+"/status/"+jobID, nil, nil, &respData)
+	if err != nil {
+		return schema.AnnotationReplyJob{}, err
+	}
+	return respData, nil
+}
+
+// WaitForReplyJob polls an annotation reply job every interval until it completes, fails or ctx is done.
+// A zero interval polls every second. A failed job is returned with an error holding its message.
+func (a *AnnotationService) WaitForReplyJob(
+	ctx context.Context,
+	action, jobID string,
+	interval time.Duration,
+) (schema.AnnotationReplyJob, error) {
+	if interval <= 0 {
+		interval = defaultJobPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := a.GetReplyJobStatus(ctx, action, jobID)
+		if err != nil {
+			return job, err
+		}
+		switch job.JobStatus {
+		case schema.JobStatusCompleted:
+			return job, nil
+		case schema.JobStatusError:
+			return job, errors.New("annotation reply job failed: " + job.ErrorMsg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *AnnotationService) setReply(
+	ctx context.Context,
+	action string,
+	req schema.AnnotationReplyRequest,
+) (schema.AnnotationReplyJob, error) {
+	var respData schema.AnnotationReplyJob
+	err := a.send(ctx, http.MethodPost, "v1/apps/annotation-reply/"+action, req, nil, &respData)
+	if err != nil {
+		return schema.AnnotationReplyJob{}, err
+	}
+	return respData, nil
+}
@@ -0,0 +1,84 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestAnnotationService(t *testing.T) {
+	annotations := func(baseURL string) *AnnotationService { return NewAnnotationService(baseURL, "key") }
+	reply := schema.AnnotationReplyRequest{EmbeddingProviderName: "openai", EmbeddingModelName: "text-embedding-3-small", ScoreThreshold: 0.9}
+	testEndpoints(t, []endpointTest{
+		{
+			name: "list",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return annotations(baseURL).List(ctx, schema.AnnotationListQuery{Page: 2, Limit: 10, Keyword: "dify"})
+			},
+			method:   http.MethodGet,
+			path:     "/v1/apps/annotations",
+			query:    "page=2&limit=10&keyword=dify",
+			response: `{"data": [{"id": "a", "question": "q", "answer": "a", "hit_count": 1}], "has_more": true, "limit": 10, "total": 11, "page": 2}`,
+			want: schema.AnnotationListResponse{
+				Data:    []schema.Annotation{{ID: "a", Question: "q", Answer: "a", HitCount: 1}},
+				HasMore: true, Limit: 10, Total: 11, Page: 2,
+			},
+		},
+		{
+			name: "create",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return annotations(baseURL).Create(ctx, schema.AnnotationRequest{Question: "q", Answer: "a"})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/apps/annotations",
+			body:     `{"question": "q", "answer": "a"}`,
+			response: `{"id": "a", "question": "q", "answer": "a"}`,
+			want:     schema.Annotation{ID: "a", Question: "q", Answer: "a"},
+		},
+		{
+			name: "update",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return annotations(baseURL).Update(ctx, "a", schema.AnnotationRequest{Question: "q", Answer: "b"})
+			},
+			method:   http.MethodPut,
+			path:     "/v1/apps/annotations/a",
+			body:     `{"question": "q", "answer": "b"}`,
+			response: `{"id": "a", "question": "q", "answer": "b"}`,
+			want:     schema.Annotation{ID: "a", Question: "q", Answer: "b"},
+		},
+		{
+			name: "delete",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, annotations(baseURL).Delete(ctx, "a")
+			},
+			method: http.MethodDelete,
+			path:   "/v1/apps/annotations/a",
+		},
+		{
+			name: "enable reply",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return annotations(baseURL).EnableReply(ctx, reply)
+			},
+			method:   http.MethodPost,
+			path:     "/v1/apps/annotation-reply/enable",
+			body:     `{"embedding_provider_name": "openai", "embedding_model_name": "text-embedding-3-small", "score_threshold": 0.9}`,
+			response: `{"job_id": "j", "job_status": "waiting"}`,
+			want:     schema.AnnotationReplyJob{JobID: "j", JobStatus: schema.JobStatusWaiting},
+		},
+		{
+			name: "reply job status",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return annotations(baseURL).GetReplyJobStatus(ctx, schema.AnnotationReplyDisable, "j")
+			},
+			method:   http.MethodGet,
+			path:     "/v1/apps/annotation-reply/disable/status/j",
+			response: `{"job_id": "j", "job_status": "error", "error_msg": "boom"}`,
+			want:     schema.AnnotationReplyJob{JobID: "j", JobStatus: schema.JobStatusError, ErrorMsg: "boom"},
+		},
+	})
+}
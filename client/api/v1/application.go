@@ -2,7 +2,6 @@ package v1
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 
 	"github.com/yeeaiclub/dify-go/internal/handler"
@@ -26,23 +25,40 @@ func NewApplication(baseURL, apiKey string) *Application {
 
 // GetParameters retrieves the application's input form configuration.
 func (app *Application) GetParameters(ctx context.Context) (schema.ApplicationParameters, error) {
-	r, err := handler.NewRequestBuilder().
-		Method(http.MethodGet).
-		BaseURL(app.baseURL).
-		Token(app.apiKey).
-		Path("v1/parameters").
-		Build()
+	var respData schema.ApplicationParameters
+	err := app.send(ctx, http.MethodGet, "v1/parameters", nil, nil, &respData)
 	if err != nil {
 		return schema.ApplicationParameters{}, err
 	}
-	resp, err := app.client.Send(ctx, r)
+	return respData, nil
+}
+
+// GetInfo retrieves the application's basic information, including its mode.
+func (app *Application) GetInfo(ctx context.Context) (schema.ApplicationInfo, error) {
+	var respData schema.ApplicationInfo
+	err := app.send(ctx, http.MethodGet, "v1/info", nil, nil, &respData)
 	if err != nil {
-		return schema.ApplicationParameters{}, err
+		return schema.ApplicationInfo{}, err
 	}
-	var respData schema.ApplicationParameters
-	err = json.Unmarshal(resp.Body, &respData)
+	return respData, nil
+}
+
+// GetMeta retrieves the application's meta information, such as the icons of its tools.
+func (app *Application) GetMeta(ctx context.Context) (schema.ApplicationMeta, error) {
+	var respData schema.ApplicationMeta
+	err := app.send(ctx, http.MethodGet, "v1/meta", nil, nil, &respData)
 	if err != nil {
-		return schema.ApplicationParameters{}, err
+		return schema.ApplicationMeta{}, err
+	}
+	return respData, nil
+}
+
+// GetSite retrieves the application's WebApp settings.
+func (app *Application) GetSite(ctx context.Context) (schema.ApplicationSite, error) {
+	var respData schema.ApplicationSite
+	err := app.send(ctx, http.MethodGet, "v1/site", nil, nil, &respData)
+	if err != nil {
+		return schema.ApplicationSite{}, err
 	}
 	return respData, nil
 }
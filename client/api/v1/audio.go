@@ -0,0 +1,122 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yeeaiclub/dify-go/internal/handler"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// MaxAudioSize is the largest audio file accepted for speech to text, in bytes.
+const MaxAudioSize = 30 << 20
+
+// audioExtensions are the audio formats accepted for speech to text.
+var audioExtensions = []string{".mp3", ".mp4", ".mpeg", ".mpga", ".m4a", ".wav", ".webm", ".amr"}
+
+// AudioService is a service for speech to text and text to speech conversion.
+type AudioService struct {
+	*BaseClient
+}
+
+// NewAudioService creates a new AudioService instance with the provided baseURL and apiKey.
+func NewAudioService(baseURL, apiKey string) *AudioService {
+	baseClient := &BaseClient{
+		client:  handler.NewClient(),
+		apiKey:  apiKey,
+		baseURL: baseURL,
+	}
+	return &AudioService{baseClient}
+}
+
+// SpeechToText converts an audio file to text. Files with an unsupported extension or larger than
+// MaxAudioSize are rejected with ErrUnsupportedAudioType or ErrAudioTooLarge before being uploaded.
+func (a *AudioService) SpeechToText(
+	ctx context.Context,
+	req schema.SpeechToTextRequest,
+) (schema.SpeechToTextResponse, error) {
+	ext := strings.ToLower(filepath.Ext(req.FileName))
+	if !slices.Contains(audioExtensions, ext) {
+		return schema.SpeechToTextResponse{}, fmt.Errorf("%w: %q", ErrUnsupportedAudioType, req.FileName)
+	}
+	data, err := io.ReadAll(io.LimitReader(req.File, MaxAudioSize+1))
+	if err != nil {
+		return schema.SpeechToTextResponse{}, fmt.Errorf("failed to read audio: %w", err)
+	}
+	if len(data) > MaxAudioSize {
+		return schema.SpeechToTextResponse{}, fmt.Errorf("%w: larger than %d bytes", ErrAudioTooLarge, MaxAudioSize)
+	}
+
+	r, err := handler.NewRequestBuilder().
+		BaseURL(a.baseURL).
+		Token(a.apiKey).
+		Path("v1/audio-to-text").
+		Method(http.MethodPost).
+		Body(&handler.MultipartBody{
+			Fields: map[string]string{"user": req.User},
+			Files: []handler.FormFile{{
+				FieldName:   "file",
+				FileName:    filepath.Base(req.FileName),
+				ContentType: mime.TypeByExtension(ext),
+				Reader:      bytes.NewReader(data),
+			}},
+		}).
+		Build()
+	if err != nil {
+		return schema.SpeechToTextResponse{}, err
+	}
+	resp, err := a.client.Send(ctx, r)
+	if err != nil {
+		return schema.SpeechToTextResponse{}, err
+	}
+	if err = checkResponse(resp); err != nil {
+		return schema.SpeechToTextResponse{}, err
+	}
+	var respData schema.SpeechToTextResponse
+	err = json.Unmarshal(resp.Body, &respData)
+	if err != nil {
+		return schema.SpeechToTextResponse{}, err
+	}
+	return respData, nil
+}
+
+// TextToAudio converts text, or the answer of a message, to speech. The audio is returned as it is
+// received so it can be streamed to clients without buffering; the caller must close it.
+// The client timeout only applies until the response starts, so long audio is not cut off;
+// use ctx to bound the whole transfer.
+func (a *AudioService) TextToAudio(ctx context.Context, req schema.TextToAudioRequest) (io.ReadCloser, error) {
+	if req.MessageID == "" && req.Text == "" {
+		return nil, errors.New("either message id or text is required")
+	}
+
+	r, err := handler.NewRequestBuilder().
+		BaseURL(a.baseURL).
+		Token(a.apiKey).
+		Path("v1/text-to-audio").
+		Method(http.MethodPost).
+		Body(req).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.client.SendRaw(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkRawResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,81 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestAudioService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/audio-to-text":
+			file, header, err := r.FormFile("file")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer file.Close()
+			data, _ := io.ReadAll(file)
+			if string(data) == "too large" {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				_, _ = w.Write([]byte(`{"code": "audio_too_large", "message": "Audio size exceeded.", "status": 413}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"text": "` + header.Filename + `:` + r.FormValue("user") + `"}`))
+		case "/v1/text-to-audio":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			_, _ = w.Write([]byte("mp3 bytes"))
+		}
+	}))
+	defer server.Close()
+	audio := NewAudioService(server.URL, "key")
+
+	t.Run("speech to text", func(t *testing.T) {
+		resp, err := audio.SpeechToText(t.Context(), schema.SpeechToTextRequest{
+			File:     strings.NewReader("wav bytes"),
+			FileName: "dir/voice.wav",
+			User:     "abc",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "voice.wav:abc", resp.Text)
+	})
+
+	t.Run("unsupported format is rejected locally", func(t *testing.T) {
+		_, err := audio.SpeechToText(t.Context(), schema.SpeechToTextRequest{
+			File:     strings.NewReader("ogg bytes"),
+			FileName: "voice.ogg",
+		})
+		assert.ErrorIs(t, err, ErrUnsupportedAudioType)
+	})
+
+	t.Run("api errors match sentinels", func(t *testing.T) {
+		_, err := audio.SpeechToText(t.Context(), schema.SpeechToTextRequest{
+			File:     strings.NewReader("too large"),
+			FileName: "voice.mp3",
+		})
+		require.ErrorIs(t, err, ErrAudioTooLarge)
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, apiErr.StatusCode)
+	})
+
+	t.Run("text to audio", func(t *testing.T) {
+		body, err := audio.TextToAudio(t.Context(), schema.TextToAudioRequest{Text: "hello", User: "abc"})
+		require.NoError(t, err)
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "mp3 bytes", string(data))
+	})
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/yeeaiclub/dify-go/internal/handler"
@@ -34,7 +36,7 @@ type PoolConfig struct {
 // BaseClient the base client of dify
 type BaseClient struct {
 	client  *handler.Client // HTTP client for making API requests
-	apiKey  string          // API key for authentication
+	apiKey  string          // API key for authentication, an app key or a dataset key depending on the service
 	baseURL string          // Base URL of the API server
 }
 
@@ -72,3 +74,34 @@ func DefaultPoolConfig() *PoolConfig {
 		IdleConnTimeout:     defaultIdleConnTimeoutSec * time.Second,
 	}
 }
+
+// send builds a request to path with the optional body and query, sends it and decodes
+// the JSON response into respData unless respData is nil. Error responses are returned as *APIError.
+func (c *BaseClient) send(ctx context.Context, method, path string, body, query, respData any) error {
+	builder := handler.NewRequestBuilder().
+		BaseURL(c.baseURL).
+		Token(c.apiKey).
+		Path(path).
+		Method(method)
+	if body != nil {
+		builder.Body(body)
+	}
+	if query != nil {
+		builder.Query(query)
+	}
+	r, err := builder.Build()
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Send(ctx, r)
+	if err != nil {
+		return err
+	}
+	if err = checkResponse(resp); err != nil {
+		return err
+	}
+	if respData == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Body, respData)
+}
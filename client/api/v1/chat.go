@@ -0,0 +1,44 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/internal/handler"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// ChatService is a client for sending messages to a chat or chatflow app.
+type ChatService struct {
+	*BaseClient
+}
+
+// NewChatService creates a new ChatService instance with the provided baseURL and apiKey.
+func NewChatService(baseURL, apiKey string) *ChatService {
+	baseClient := &BaseClient{
+		client:  handler.NewClient(),
+		apiKey:  apiKey,
+		baseURL: baseURL,
+	}
+	return &ChatService{baseClient}
+}
+
+// SendMessage sends a message in blocking mode and returns the answer.
+func (c *ChatService) SendMessage(
+	ctx context.Context,
+	req schema.ChatMessageRequest,
+) (schema.MessageResponse, error) {
+	if req.ResponseMode != BlockingMode {
+		return schema.MessageResponse{}, errors.New("response mode must be blocking")
+	}
+	var respData schema.MessageResponse
+	err := c.send(ctx, http.MethodPost, "v1/chat-messages", req, nil, &respData)
+	if err != nil {
+		return schema.MessageResponse{}, err
+	}
+	return respData, nil
+}
@@ -0,0 +1,62 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestSendMessage(t *testing.T) {
+	testEndpoints(t, []endpointTest{
+		{
+			name: "chat",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return NewChatService(baseURL, "key").SendMessage(ctx, schema.ChatMessageRequest{
+					Query:          "hello",
+					Inputs:         json.RawMessage(`{"lang": "en"}`),
+					ResponseMode:   BlockingMode,
+					User:           "abc",
+					ConversationID: "c",
+				})
+			},
+			method: http.MethodPost,
+			path:   "/v1/chat-messages",
+			body: `{"query": "hello", "inputs": {"lang": "en"}, "response_mode": "blocking", "user": "abc",
+				"conversation_id": "c"}`,
+			response: `{"event": "message", "message_id": "m", "conversation_id": "c", "mode": "chat", "answer": "hi",
+				"metadata": {"usage": {"total_tokens": 12}}, "created_at": 1700000000}`,
+			want: schema.MessageResponse{
+				Event: "message", MessageID: "m", ConversationID: "c", Mode: "chat", Answer: "hi",
+				Metadata: schema.MessageMetadata{Usage: schema.Usage{TotalTokens: 12}}, CreatedAt: 1700000000,
+			},
+		},
+		{
+			name: "completion",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return NewCompletionService(baseURL, "key").SendMessage(ctx, schema.CompletionMessageRequest{
+					Inputs:       json.RawMessage(`{"query": "summarize"}`),
+					ResponseMode: BlockingMode,
+					User:         "abc",
+				})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/completion-messages",
+			body:     `{"inputs": {"query": "summarize"}, "response_mode": "blocking", "user": "abc"}`,
+			response: `{"event": "message", "message_id": "m", "mode": "completion", "answer": "summary"}`,
+			want:     schema.MessageResponse{Event: "message", MessageID: "m", Mode: "completion", Answer: "summary"},
+		},
+	})
+
+	_, err := NewChatService("http://dify", "key").SendMessage(t.Context(), schema.ChatMessageRequest{ResponseMode: StreamMode})
+	assert.Error(t, err)
+	_, err = NewCompletionService("http://dify", "key").SendMessage(t.Context(), schema.CompletionMessageRequest{})
+	assert.Error(t, err)
+}
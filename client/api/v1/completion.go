@@ -0,0 +1,44 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/internal/handler"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// CompletionService is a client for sending messages to a text generation app.
+type CompletionService struct {
+	*BaseClient
+}
+
+// NewCompletionService creates a new CompletionService instance with the provided baseURL and apiKey.
+func NewCompletionService(baseURL, apiKey string) *CompletionService {
+	baseClient := &BaseClient{
+		client:  handler.NewClient(),
+		apiKey:  apiKey,
+		baseURL: baseURL,
+	}
+	return &CompletionService{baseClient}
+}
+
+// SendMessage sends a message in blocking mode and returns the generated text.
+func (c *CompletionService) SendMessage(
+	ctx context.Context,
+	req schema.CompletionMessageRequest,
+) (schema.MessageResponse, error) {
+	if req.ResponseMode != BlockingMode {
+		return schema.MessageResponse{}, errors.New("response mode must be blocking")
+	}
+	var respData schema.MessageResponse
+	err := c.send(ctx, http.MethodPost, "v1/completion-messages", req, nil, &respData)
+	if err != nil {
+		return schema.MessageResponse{}, err
+	}
+	return respData, nil
+}
@@ -0,0 +1,86 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/internal/handler"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// DatasetService is a client for the knowledge API. Unlike the app services it authenticates with a
+// dataset API key, which is shared by every knowledge base of the workspace.
+type DatasetService struct {
+	*BaseClient
+}
+
+// NewDatasetService creates a new DatasetService instance with the provided baseURL and dataset API key.
+func NewDatasetService(baseURL, datasetAPIKey string) *DatasetService {
+	baseClient := &BaseClient{
+		client:  handler.NewClient(),
+		apiKey:  datasetAPIKey,
+		baseURL: baseURL,
+	}
+	return &DatasetService{baseClient}
+}
+
+// Create creates an empty dataset.
+func (d *DatasetService) Create(ctx context.Context, req schema.CreateDatasetRequest) (schema.Dataset, error) {
+	var respData schema.Dataset
+	err := d.send(ctx, http.MethodPost, "v1/datasets", req, nil, &respData)
+	if err != nil {
+		return schema.Dataset{}, err
+	}
+	return respData, nil
+}
+
+// List retrieves a page of datasets, optionally filtered by keyword and tags.
+func (d *DatasetService) List(ctx context.Context, query schema.DatasetListQuery) (schema.DatasetListResponse, error) {
+	var respData schema.DatasetListResponse
+	err := d.send(ctx, http.MethodGet, "v1/datasets", nil, query, &respData)
+	if err != nil {
+		return schema.DatasetListResponse{}, err
+	}
+	return respData, nil
+}
+
+// Get retrieves a dataset.
+func (d *DatasetService) Get(ctx context.Context, datasetID string) (schema.Dataset, error) {
+	var respData schema.Dataset
+	err := d.send(ctx, http.MethodGet, datasetPath(datasetID), nil, nil, &respData)
+	if err != nil {
+		return schema.Dataset{}, err
+	}
+	return respData, nil
+}
+
+// Update updates the settings of a dataset.
+func (d *DatasetService) Update(
+	ctx context.Context,
+	datasetID string,
+	req schema.UpdateDatasetRequest,
+) (schema.Dataset, error) {
+	var respData schema.Dataset
+	err := d.send(ctx, http.MethodPatch, datasetPath(datasetID), req, nil, &respData)
+	if err != nil {
+		return schema.Dataset{}, err
+	}
+	return respData, nil
+}
+
+// Delete deletes a dataset and its documents.
+func (d *DatasetService) Delete(ctx context.Context, datasetID string) error {
+	return d.send(ctx, http.MethodDelete, datasetPath(datasetID), nil, nil, nil)
+}
+
+// datasetPath returns the API path of a dataset, joined with the optional sub-path elements.
+func datasetPath(datasetID string, elem ...string) string {
+	p := "v1/datasets/" + datasetID
+	for _, e := range elem {
+		p += "/" + e
+	}
+	return p
+}
@@ -0,0 +1,84 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestDatasetService(t *testing.T) {
+	datasets := func(baseURL string) *DatasetService { return NewDatasetService(baseURL, "key") }
+	testEndpoints(t, []endpointTest{
+		{
+			name: "create",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).Create(ctx, schema.CreateDatasetRequest{
+					Name:              "docs",
+					IndexingTechnique: schema.IndexingTechniqueHighQuality,
+					RetrievalModel:    &schema.RetrievalModel{SearchMethod: schema.SearchMethodSemantic, TopK: 3},
+				})
+			},
+			method: http.MethodPost,
+			path:   "/v1/datasets",
+			body: `{"name": "docs", "indexing_technique": "high_quality", "retrieval_model": {
+				"search_method": "semantic_search", "reranking_enable": false, "top_k": 3,
+				"score_threshold_enabled": false, "score_threshold": 0}}`,
+			response: `{"id": "ds", "name": "docs", "retrieval_model_dict": {"search_method": "semantic_search", "top_k": 3}}`,
+			want: schema.Dataset{
+				ID: "ds", Name: "docs",
+				RetrievalModel: schema.RetrievalModel{SearchMethod: schema.SearchMethodSemantic, TopK: 3},
+			},
+		},
+		{
+			name: "list",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).List(ctx, schema.DatasetListQuery{TagIDs: []string{"t1", "t2"}, Page: 1, IncludeAll: true})
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets",
+			query:    "tag_ids=t1&tag_ids=t2&page=1&include_all=true",
+			response: `{"data": [{"id": "ds", "tags": [{"id": "t1", "name": "faq"}]}], "has_more": false, "page": 1}`,
+			want: schema.DatasetListResponse{
+				Data: []schema.Dataset{{ID: "ds", Tags: []schema.DatasetTag{{ID: "t1", Name: "faq"}}}},
+				Page: 1,
+			},
+		},
+		{
+			name: "get",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).Get(ctx, "ds")
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds",
+			response: `{"id": "ds", "document_count": 2}`,
+			want:     schema.Dataset{ID: "ds", DocumentCount: 2},
+		},
+		{
+			name: "update partial members",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).Update(ctx, "ds", schema.UpdateDatasetRequest{
+					Permission:        schema.DatasetPermissionPartialMembers,
+					PartialMemberList: []schema.DatasetMember{{UserID: "u1"}},
+				})
+			},
+			method:   http.MethodPatch,
+			path:     "/v1/datasets/ds",
+			body:     `{"permission": "partial_members", "partial_member_list": [{"user_id": "u1"}]}`,
+			response: `{"id": "ds", "permission": "partial_members"}`,
+			want:     schema.Dataset{ID: "ds", Permission: schema.DatasetPermissionPartialMembers},
+		},
+		{
+			name: "delete",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).Delete(ctx, "ds")
+			},
+			method: http.MethodDelete,
+			path:   "/v1/datasets/ds",
+		},
+	})
+}
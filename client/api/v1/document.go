@@ -0,0 +1,164 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/yeeaiclub/dify-go/internal/handler"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// CreateDocumentByText creates a document from text. Indexing runs asynchronously;
+// the returned batch can be used to follow it.
+func (d *DatasetService) CreateDocumentByText(
+	ctx context.Context,
+	datasetID string,
+	req schema.CreateDocumentByTextRequest,
+) (schema.DocumentResponse, error) {
+	var respData schema.DocumentResponse
+	err := d.send(ctx, http.MethodPost, datasetPath(datasetID, "document/create-by-text"), req, nil, &respData)
+	if err != nil {
+		return schema.DocumentResponse{}, err
+	}
+	return respData, nil
+}
+
+// CreateDocumentByFile uploads a file and creates a document from it. Indexing runs asynchronously;
+// the returned batch can be used to follow it.
+func (d *DatasetService) CreateDocumentByFile(
+	ctx context.Context,
+	datasetID string,
+	req schema.CreateDocumentByFileRequest,
+) (schema.DocumentResponse, error) {
+	body, err := documentFileBody(req.File, req.FileName, req)
+	if err != nil {
+		return schema.DocumentResponse{}, err
+	}
+	var respData schema.DocumentResponse
+	err = d.send(ctx, http.MethodPost, datasetPath(datasetID, "document/create-by-file"), body, nil, &respData)
+	if err != nil {
+		return schema.DocumentResponse{}, err
+	}
+	return respData, nil
+}
+
+// UpdateDocumentByText replaces the content of a document with text and re-indexes it.
+func (d *DatasetService) UpdateDocumentByText(
+	ctx context.Context,
+	datasetID, documentID string,
+	req schema.UpdateDocumentByTextRequest,
+) (schema.DocumentResponse, error) {
+	var respData schema.DocumentResponse
+	err := d.send(ctx, http.MethodPost, datasetPath(datasetID, "documents", documentID, "update-by-text"),
+		req, nil, &respData)
+	if err != nil {
+		return schema.DocumentResponse{}, err
+	}
+	return respData, nil
+}
+
+// UpdateDocumentByFile replaces the content of a document with a file and re-indexes it.
+func (d *DatasetService) UpdateDocumentByFile(
+	ctx context.Context,
+	datasetID, documentID string,
+	req schema.UpdateDocumentByFileRequest,
+) (schema.DocumentResponse, error) {
+	body, err := documentFileBody(req.File, req.FileName, req)
+	if err != nil {
+		return schema.DocumentResponse{}, err
+	}
+	var respData schema.DocumentResponse
+	err = d.send(ctx, http.MethodPost, datasetPath(datasetID, "documents", documentID, "update-by-file"),
+		body, nil, &respData)
+	if err != nil {
+		return schema.DocumentResponse{}, err
+	}
+	return respData, nil
+}
+
+// documentFileBody builds the multipart body of file based document requests: the file itself
+// and the JSON encoded settings in the data field.
+func documentFileBody(file io.Reader, fileName string, data any) (*handler.MultipartBody, error) {
+	if file == nil || fileName == "" {
+		return nil, errors.New("file and file name are required")
+	}
+	settings, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document settings: %w", err)
+	}
+	return &handler.MultipartBody{
+		Fields: map[string]string{"data": string(settings)},
+		Files: []handler.FormFile{{
+			FieldName:   "file",
+			FileName:    filepath.Base(fileName),
+			ContentType: mime.TypeByExtension(filepath.Ext(fileName)),
+			Reader:      file,
+		}},
+	}, nil
+}
+
+// ListDocuments retrieves a page of the documents of a dataset, optionally filtered by keyword.
+func (d *DatasetService) ListDocuments(
+	ctx context.Context,
+	datasetID string,
+	query schema.DocumentListQuery,
+) (schema.DocumentListResponse, error) {
+	var respData schema.DocumentListResponse
+	err := d.send(ctx, http.MethodGet, datasetPath(datasetID, "documents"), nil, query, &respData)
+	if err != nil {
+		return schema.DocumentListResponse{}, err
+	}
+	return respData, nil
+}
+
+// GetDocument retrieves a document with its metadata, processing settings and segment statistics.
+func (d *DatasetService) GetDocument(
+	ctx context.Context,
+	datasetID, documentID string,
+	query schema.DocumentDetailQuery,
+) (schema.DocumentDetail, error) {
+	var respData schema.DocumentDetail
+	err := d.send(ctx, http.MethodGet, datasetPath(datasetID, "documents", documentID), nil, query, &respData)
+	if err != nil {
+		return schema.DocumentDetail{}, err
+	}
+	return respData, nil
+}
+
+// UpdateDocumentStatus enables, disables, archives or unarchives documents in a batch.
+// action is one of the schema.DocumentAction constants.
+func (d *DatasetService) UpdateDocumentStatus(
+	ctx context.Context,
+	datasetID, action string,
+	documentIDs []string,
+) error {
+	req := schema.DocumentStatusRequest{DocumentIDs: documentIDs}
+	return d.send(ctx, http.MethodPatch, datasetPath(datasetID, "documents/status", action), req, nil, nil)
+}
+
+// DeleteDocument deletes a document and its segments.
+func (d *DatasetService) DeleteDocument(ctx context.Context, datasetID, documentID string) error {
+	return d.send(ctx, http.MethodDelete, datasetPath(datasetID, "documents", documentID), nil, nil, nil)
+}
+
+// GetDocumentUploadFile retrieves the original file a document was created from.
+func (d *DatasetService) GetDocumentUploadFile(
+	ctx context.Context,
+	datasetID, documentID string,
+) (schema.DocumentUploadFile, error) {
+	var respData schema.DocumentUploadFile
+	err := d.send(ctx, http.MethodGet, datasetPath(datasetID, "documents", documentID, "upload-file"), nil, nil, &respData)
+	if err != nil {
+		return schema.DocumentUploadFile{}, err
+	}
+	return respData, nil
+}
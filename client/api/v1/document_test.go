@@ -0,0 +1,158 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// checkDocumentFile returns a check of a multipart document request sending the given file and settings.
+func checkDocumentFile(fileName, content, data string) func(t *testing.T, r *http.Request) {
+	return func(t *testing.T, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer file.Close()
+		got, _ := io.ReadAll(file)
+		assert.Equal(t, fileName, header.Filename)
+		assert.Equal(t, content, string(got))
+		assert.JSONEq(t, data, r.FormValue("data"))
+	}
+}
+
+func TestDocumentService(t *testing.T) {
+	datasets := func(baseURL string) *DatasetService { return NewDatasetService(baseURL, "key") }
+	automatic := &schema.ProcessRule{Mode: schema.ProcessModeAutomatic}
+	testEndpoints(t, []endpointTest{
+		{
+			name: "create by text",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).CreateDocumentByText(ctx, "ds", schema.CreateDocumentByTextRequest{
+					Name: "faq",
+					Text: "hello",
+					DocumentSettings: schema.DocumentSettings{
+						IndexingTechnique: schema.IndexingTechniqueEconomy,
+						ProcessRule:       automatic,
+					},
+				})
+			},
+			method: http.MethodPost,
+			path:   "/v1/datasets/ds/document/create-by-text",
+			body: `{"name": "faq", "text": "hello", "indexing_technique": "economy",
+				"process_rule": {"mode": "automatic"}}`,
+			response: `{"document": {"id": "doc", "name": "faq", "indexing_status": "waiting"}, "batch": "b1"}`,
+			want: schema.DocumentResponse{
+				Document: schema.Document{ID: "doc", Name: "faq", IndexingStatus: schema.IndexingStatusWaiting},
+				Batch:    "b1",
+			},
+		},
+		{
+			name: "create by file",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).CreateDocumentByFile(ctx, "ds", schema.CreateDocumentByFileRequest{
+					File:               strings.NewReader("file content"),
+					FileName:           "dir/faq.md",
+					DocumentSettings:   schema.DocumentSettings{ProcessRule: automatic},
+					OriginalDocumentID: "old",
+				})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/ds/document/create-by-file",
+			check:    checkDocumentFile("faq.md", "file content", `{"process_rule": {"mode": "automatic"}, "original_document_id": "old"}`),
+			response: `{"document": {"id": "doc"}, "batch": "b2"}`,
+			want:     schema.DocumentResponse{Document: schema.Document{ID: "doc"}, Batch: "b2"},
+		},
+		{
+			name: "update by text",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).UpdateDocumentByText(ctx, "ds", "doc", schema.UpdateDocumentByTextRequest{Text: "updated"})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/ds/documents/doc/update-by-text",
+			body:     `{"text": "updated"}`,
+			response: `{"document": {"id": "doc"}, "batch": "b3"}`,
+			want:     schema.DocumentResponse{Document: schema.Document{ID: "doc"}, Batch: "b3"},
+		},
+		{
+			name: "update by file",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).UpdateDocumentByFile(ctx, "ds", "doc", schema.UpdateDocumentByFileRequest{
+					File:     strings.NewReader("new content"),
+					FileName: "faq.md",
+					Name:     "FAQ",
+				})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/ds/documents/doc/update-by-file",
+			check:    checkDocumentFile("faq.md", "new content", `{"name": "FAQ"}`),
+			response: `{"document": {"id": "doc", "name": "FAQ"}, "batch": "b4"}`,
+			want:     schema.DocumentResponse{Document: schema.Document{ID: "doc", Name: "FAQ"}, Batch: "b4"},
+		},
+		{
+			name: "list",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).ListDocuments(ctx, "ds", schema.DocumentListQuery{Keyword: "faq", Status: "available", Page: 2})
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds/documents",
+			query:    "keyword=faq&status=available&page=2",
+			response: `{"data": [{"id": "doc", "enabled": true}], "has_more": false, "page": 2, "total": 21}`,
+			want: schema.DocumentListResponse{
+				Data: []schema.Document{{ID: "doc", Enabled: true}},
+				Page: 2, Total: 21,
+			},
+		},
+		{
+			name: "get",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).GetDocument(ctx, "ds", "doc", schema.DocumentDetailQuery{Metadata: schema.DocumentMetadataWithout})
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds/documents/doc",
+			query:    "metadata=without",
+			response: `{"id": "doc", "segment_count": 4, "document_process_rule": {"mode": "automatic"}}`,
+			want: schema.DocumentDetail{
+				Document:            schema.Document{ID: "doc"},
+				SegmentCount:        4,
+				DocumentProcessRule: &schema.ProcessRule{Mode: schema.ProcessModeAutomatic},
+			},
+		},
+		{
+			name: "update status",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).UpdateDocumentStatus(ctx, "ds", schema.DocumentActionDisable, []string{"a", "b"})
+			},
+			method: http.MethodPatch,
+			path:   "/v1/datasets/ds/documents/status/disable",
+			body:   `{"document_ids": ["a", "b"]}`,
+		},
+		{
+			name: "delete",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).DeleteDocument(ctx, "ds", "doc")
+			},
+			method: http.MethodDelete,
+			path:   "/v1/datasets/ds/documents/doc",
+		},
+		{
+			name: "upload file",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).GetDocumentUploadFile(ctx, "ds", "doc")
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds/documents/doc/upload-file",
+			response: `{"id": "f", "name": "faq.md", "size": 12, "extension": "md"}`,
+			want:     schema.DocumentUploadFile{ID: "f", Name: "faq.md", Size: 12, Extension: "md"},
+		},
+	})
+}
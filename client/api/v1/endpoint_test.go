@@ -0,0 +1,64 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endpointTest describes the request an endpoint is expected to send and the response it decodes.
+type endpointTest struct {
+	name string
+	// call calls the endpoint on a service created for the base URL of the test server.
+	call   func(ctx context.Context, baseURL string) (any, error)
+	method string
+	path   string
+	// query is the expected raw query.
+	query string
+	// body is the expected JSON body, empty when no body is sent.
+	body string
+	// check replaces the body check, for example for multipart requests.
+	check    func(t *testing.T, r *http.Request)
+	response string
+	want     any
+}
+
+// testEndpoints runs each test against a server checking the request and serving the response.
+func testEndpoints(t *testing.T, tests []endpointTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.method, r.Method)
+				assert.Equal(t, tt.path, r.URL.Path)
+				query, err := url.ParseQuery(tt.query)
+				assert.NoError(t, err)
+				assert.Equal(t, query, r.URL.Query())
+				assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+				if tt.check != nil {
+					tt.check(t, r)
+				} else if body, _ := io.ReadAll(r.Body); tt.body == "" {
+					assert.Empty(t, body)
+				} else {
+					assert.JSONEq(t, tt.body, string(body))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, tt.response)
+			}))
+			defer server.Close()
+
+			got, err := tt.call(t.Context(), server.URL)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/internal/handler"
+)
+
+// Error codes returned by dify that have a matching sentinel error.
+const (
+	codeUnsupportedAudioType = "unsupported_audio_type"
+	codeAudioTooLarge        = "audio_too_large"
+)
+
+var (
+	// ErrUnsupportedAudioType is returned when an audio file has a format dify cannot transcribe.
+	ErrUnsupportedAudioType = &APIError{Code: codeUnsupportedAudioType, Message: "unsupported audio type"}
+	// ErrAudioTooLarge is returned when an audio file exceeds the size accepted by dify.
+	ErrAudioTooLarge = &APIError{Code: codeAudioTooLarge, Message: "audio too large"}
+)
+
+// APIError is an error response returned by the dify API.
+type APIError struct {
+	StatusCode int    `json:"status"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("dify: %s: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("dify: HTTP %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is reports whether target is an APIError with the same code, so that responses
+// can be matched against the sentinel errors with errors.Is.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// IsTransient reports whether a request that failed with err may succeed when sent again: it
+// failed with a network error, including a client timeout, or dify answered with HTTP 429 or a
+// server error. Invalid requests, undecodable responses and canceled requests are not transient.
+// A client timeout also matches context.DeadlineExceeded, so callers should check their own
+// context rather than the error to tell that it is done.
+func IsTransient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	// Requests that could not be sent or answered fail with a *url.Error, which is a net.Error.
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// checkResponse returns an *APIError when resp has an error status code.
+func checkResponse(resp *handler.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	return newAPIError(resp.StatusCode, resp.Body)
+}
+
+// checkRawResponse returns an *APIError when resp has an error status code, closing its body.
+func checkRawResponse(resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read error response: %w", err)
+	}
+	return newAPIError(resp.StatusCode, body)
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{}
+	if json.Unmarshal(body, apiErr) != nil || apiErr.Code == "" {
+		apiErr.Message = string(body)
+	}
+	apiErr.StatusCode = status
+	return apiErr
+}
@@ -0,0 +1,47 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	client := &http.Client{Timeout: time.Millisecond}
+	_, timeoutErr := client.Get(server.URL)
+	require.Error(t, timeoutErr)
+	// A client timeout looks like a context deadline, but dify may answer the next request.
+	require.ErrorIs(t, timeoutErr, context.DeadlineExceeded)
+
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{fmt.Errorf("run: %w", &APIError{StatusCode: http.StatusBadGateway}), true},
+		{&APIError{StatusCode: http.StatusBadRequest, Code: "invalid_param"}, false},
+		{fmt.Errorf("failed to send HTTP request: %w", timeoutErr), true},
+		{&ValidationError{Fields: []FieldError{{Variable: "x", Message: "is missing"}}}, false},
+		{json.Unmarshal([]byte("{"), &struct{}{}), false},
+		{context.Canceled, false},
+		{errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsTransient(tt.err), tt.err.Error())
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+const (
+	// defaultRunPollInterval is the delay between run detail requests when no interval is given.
+	defaultRunPollInterval = 2 * time.Second
+	// maxRunPollBackoff bounds the delay between run detail requests after transient failures.
+	maxRunPollBackoff = 30 * time.Second
+	// maxRunPollFailures is the number of consecutive transient failures after which polling gives up.
+	maxRunPollFailures = 5
+	// progressBuffer is the number of node events buffered for a handle's progress channel.
+	progressBuffer = 64
+)
+
+// ErrNoTask is returned by RunHandle.Cancel for resumed runs, whose task ID is unknown.
+var ErrNoTask = errors.New("run has no task ID")
+
+// ErrDetached is returned by RunHandle.Wait when the handle was detached before the run finished.
+var ErrDetached = errors.New("run handle detached")
+
+// NodeEvent is a node_started or node_finished event of a run.
+type NodeEvent struct {
+	Event string
+	Node  schema.NodeEventData
+}
+
+// RunHandle follows a workflow run executing in the background.
+type RunHandle struct {
+	w        *WorkflowService
+	taskID   string
+	runID    string
+	user     string
+	interval time.Duration
+	progress chan NodeEvent
+	done     chan struct{}
+	stop     context.CancelFunc
+
+	mu     sync.Mutex
+	status string
+	result schema.RunWorkflowResponse
+	err    error
+}
+
+// Start executes a workflow in streaming mode in the background and returns once the run started,
+// whatever req.ResponseMode. ctx only bounds the start of the run. If the stream breaks before the
+// run finishes, the handle falls back to polling the run detail. The handle follows the run until
+// it finishes or Detach is called.
+func (w *WorkflowService) Start(ctx context.Context, req schema.RunWorkflowRequest) (*RunHandle, error) {
+	req.ResponseMode = StreamMode
+	streamCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	stream, err := w.RunStream(streamCtx, req)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+
+	h := newRunHandle(w, req.User, defaultRunPollInterval, stop)
+	started := make(chan error, 1)
+	go h.follow(streamCtx, DecodeWorkflowEvents(stream), started)
+	select {
+	case err = <-started:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	return h, nil
+}
+
+// Resume returns a handle for a run started earlier, for instance by another process, and polls the
+// run detail every interval until it finishes. The progress channel of a resumed run is closed
+// without events, and the run cannot be canceled since its task ID is unknown.
+// Default interval: 2 seconds
+func (w *WorkflowService) Resume(ctx context.Context, runID string, interval time.Duration) (*RunHandle, error) {
+	if interval <= 0 {
+		interval = defaultRunPollInterval
+	}
+	run, err := w.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	pollCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	h := newRunHandle(w, "", interval, stop)
+	h.runID = runID
+	close(h.progress)
+	if run.Status != schema.WorkflowStatusRunning {
+		h.finishRun(run)
+		return h, nil
+	}
+	h.setStatus(run.Status)
+	go h.poll(pollCtx)
+	return h, nil
+}
+
+func newRunHandle(w *WorkflowService, user string, interval time.Duration, stop context.CancelFunc) *RunHandle {
+	return &RunHandle{
+		w:        w,
+		user:     user,
+		interval: interval,
+		progress: make(chan NodeEvent, progressBuffer),
+		done:     make(chan struct{}),
+		stop:     stop,
+	}
+}
+
+// TaskID returns the task ID used to stop the run. It is empty for resumed runs.
+func (h *RunHandle) TaskID() string {
+	return h.taskID
+}
+
+// RunID returns the ID of the run.
+func (h *RunHandle) RunID() string {
+	return h.runID
+}
+
+// Status returns the last known status of the run, see the schema.WorkflowStatus constants.
+func (h *RunHandle) Status() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// Progress returns a channel of the node events of the run, closed when the run finishes.
+// Events are dropped when the channel buffer is full, so a slow reader never delays the run.
+func (h *RunHandle) Progress() <-chan NodeEvent {
+	return h.progress
+}
+
+// Done returns a channel closed when the run finished.
+func (h *RunHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait waits for the run to finish and returns its result. Like Run, it returns no error for runs
+// that finished without succeeding; check the status of the result.
+func (h *RunHandle) Wait(ctx context.Context) (schema.RunWorkflowResponse, error) {
+	select {
+	case <-h.done:
+	case <-ctx.Done():
+		return schema.RunWorkflowResponse{}, ctx.Err()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.result, h.err
+}
+
+// Detach stops following the run and releases its connection; the run goes on server side and can
+// be followed again with Resume. Unless the run finished before, Wait then returns ErrDetached.
+func (h *RunHandle) Detach() {
+	h.stop()
+}
+
+// Cancel asks dify to stop the run. The run finishes with the stopped status, which Wait reports.
+func (h *RunHandle) Cancel(ctx context.Context) error {
+	if h.taskID == "" {
+		return ErrNoTask
+	}
+	return h.w.Stop(ctx, h.taskID, h.user)
+}
+
+// follow consumes the events of the run, reporting the start of the run to started.
+func (h *RunHandle) follow(ctx context.Context, events iter.Seq2[schema.WorkflowStreamEvent, error], started chan<- error) {
+	defer close(h.progress)
+	for event, err := range events {
+		if err != nil {
+			if h.runID == "" {
+				started <- err
+				h.finish(schema.RunWorkflowResponse{}, err)
+				return
+			}
+			// The connection broke; the run goes on server side.
+			break
+		}
+		if h.runID == "" && event.WorkflowRunID != "" {
+			h.taskID, h.runID = event.TaskID, event.WorkflowRunID
+			h.setStatus(schema.WorkflowStatusRunning)
+			started <- nil
+		}
+
+		switch event.Event {
+		case schema.EventNodeStarted, schema.EventNodeFinished:
+			var node schema.NodeEventData
+			if err := json.Unmarshal(event.Data, &node); err != nil {
+				continue
+			}
+			select {
+			case h.progress <- NodeEvent{Event: event.Event, Node: node}:
+			default:
+			}
+		case schema.EventWorkflowFinished:
+			resp := schema.RunWorkflowResponse{Event: event.Event, WorkflowRunID: h.runID, TaskID: h.taskID}
+			if err := json.Unmarshal(event.Data, &resp.Data); err != nil {
+				h.finish(resp, fmt.Errorf("failed to decode workflow_finished event: %w", err))
+				return
+			}
+			h.finish(resp, nil)
+			return
+		}
+	}
+
+	if h.runID == "" {
+		err := errors.New("workflow stream ended before the run started")
+		started <- err
+		h.finish(schema.RunWorkflowResponse{}, err)
+		return
+	}
+	h.poll(ctx)
+}
+
+// poll requests the run detail until the run finishes. Transient failures are retried with a
+// backoff starting at the poll interval.
+func (h *RunHandle) poll(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	backoff, failures := h.interval, 0
+	for {
+		select {
+		case <-ctx.Done():
+			h.finish(schema.RunWorkflowResponse{}, ErrDetached)
+			return
+		case <-timer.C:
+		}
+		run, err := h.w.GetRun(ctx, h.runID)
+		switch {
+		case ctx.Err() != nil:
+			// The handle was detached during the request.
+			continue
+		case err != nil && IsTransient(err) && failures < maxRunPollFailures:
+			failures++
+			timer.Reset(backoff)
+			backoff = min(backoff*2, max(maxRunPollBackoff, h.interval))
+			continue
+		case err != nil:
+			h.finish(schema.RunWorkflowResponse{}, err)
+			return
+		case run.Status != schema.WorkflowStatusRunning:
+			h.finishRun(run)
+			return
+		}
+		backoff, failures = h.interval, 0
+		timer.Reset(h.interval)
+	}
+}
+
+// finishRun finishes the handle with the result of a run detail.
+func (h *RunHandle) finishRun(run schema.WorkflowRun) {
+	resp := schema.RunWorkflowResponse{
+		Event:         schema.EventWorkflowFinished,
+		WorkflowRunID: run.ID,
+		TaskID:        h.taskID,
+		Data: schema.RunWorkflowResponseData{
+			ID:          run.ID,
+			WorkflowID:  run.WorkflowID,
+			Status:      run.Status,
+			Error:       run.Error,
+			ElapsedTime: run.ElapsedTime,
+			TotalToken:  run.TotalTokens,
+			TotalSteps:  run.TotalSteps,
+			CreatedAt:   run.CreatedAt,
+			FinishedAt:  run.FinishedAt,
+		},
+	}
+	outputs, err := decodeJSONObject(run.Outputs)
+	if err != nil {
+		h.finish(resp, fmt.Errorf("failed to decode run outputs: %w", err))
+		return
+	}
+	resp.Data.Outputs = outputs
+	h.finish(resp, nil)
+}
+
+func (h *RunHandle) setStatus(status string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status = status
+}
+
+// finish records the result of the run and releases the waiters.
+func (h *RunHandle) finish(resp schema.RunWorkflowResponse, err error) {
+	h.mu.Lock()
+	h.result, h.err = resp, err
+	if resp.Data.Status != "" {
+		h.status = resp.Data.Status
+	}
+	h.mu.Unlock()
+	h.stop()
+	close(h.done)
+}
+
+// decodeJSONObject decodes a JSON object, which may be encoded in a JSON string.
+func decodeJSONObject(data json.RawMessage) (map[string]any, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		if s == "" {
+			return nil, nil
+		}
+		data = json.RawMessage(s)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
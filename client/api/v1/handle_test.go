@@ -0,0 +1,130 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestRunHandle(t *testing.T) {
+	stopped := make(chan struct{})
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/workflows/run":
+			flusher := w.(http.Flusher)
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, event := range []string{
+				`{"event": "workflow_started", "task_id": "task", "workflow_run_id": "run", "data": {}}`,
+				`{"event": "node_started", "task_id": "task", "workflow_run_id": "run", "data": {"node_id": "llm", "title": "LLM"}}`,
+				`{"event": "node_finished", "task_id": "task", "workflow_run_id": "run", "data": {"node_id": "llm", "status": "succeeded"}}`,
+			} {
+				fmt.Fprintf(w, "data: %s\n\n", event)
+			}
+			flusher.Flush()
+			<-stopped
+			fmt.Fprintf(w, "data: %s\n\n", `{"event": "workflow_finished", "task_id": "task", "workflow_run_id": "run", "data": {"status": "stopped", "total_tokens": 3}}`)
+		case "/v1/workflows/tasks/task/stop":
+			var req schema.StopWorkflowRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "abc", req.User)
+			close(stopped)
+			fmt.Fprint(w, `{"result": "success"}`)
+		case "/v1/workflows/run/old":
+			status := "running"
+			switch polls.Add(1) {
+			case 1:
+			case 2:
+				// A transient failure is retried.
+				http.Error(w, `{"code": "unavailable"}`, http.StatusServiceUnavailable)
+				return
+			default:
+				status = "succeeded"
+			}
+			fmt.Fprintf(w, `{"id": "old", "status": %q, "outputs": "{\"text\": \"done\"}", "total_tokens": 7}`, status)
+		case "/v1/workflows/run/forever":
+			fmt.Fprint(w, `{"id": "forever", "status": "running"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	workflow := NewWorkflowService(server.URL, "key")
+
+	t.Run("start and cancel", func(t *testing.T) {
+		h, err := workflow.Start(t.Context(), schema.RunWorkflowRequest{Inputs: json.RawMessage(`{}`), User: "abc"})
+		require.NoError(t, err)
+		assert.Equal(t, "task", h.TaskID())
+		assert.Equal(t, "run", h.RunID())
+		assert.Equal(t, schema.WorkflowStatusRunning, h.Status())
+
+		first := <-h.Progress()
+		assert.Equal(t, schema.EventNodeStarted, first.Event)
+		assert.Equal(t, "LLM", first.Node.Title)
+
+		require.NoError(t, h.Cancel(t.Context()))
+		resp, err := h.Wait(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, schema.WorkflowStatusStopped, resp.Data.Status)
+		assert.Equal(t, schema.WorkflowStatusStopped, h.Status())
+	})
+
+	t.Run("resume by run ID", func(t *testing.T) {
+		h, err := workflow.Resume(t.Context(), "old", time.Millisecond)
+		require.NoError(t, err)
+		assert.ErrorIs(t, h.Cancel(t.Context()), ErrNoTask)
+		resp, err := h.Wait(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, schema.WorkflowStatusSucceeded, resp.Data.Status)
+		assert.Equal(t, "done", resp.Data.Outputs["text"])
+		assert.Equal(t, 7, resp.Data.TotalToken)
+	})
+
+	t.Run("detach", func(t *testing.T) {
+		h, err := workflow.Resume(t.Context(), "forever", time.Millisecond)
+		require.NoError(t, err)
+		h.Detach()
+		_, err = h.Wait(t.Context())
+		assert.ErrorIs(t, err, ErrDetached)
+	})
+}
+
+func TestRunHandleStreamBreak(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/workflows/run":
+			// The stream ends before the run finishes.
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: %s\n\n", `{"event": "workflow_started", "task_id": "task", "workflow_run_id": "run", "data": {}}`)
+		case "/v1/workflows/run/run":
+			fmt.Fprint(w, `{"id": "run", "status": "succeeded", "outputs": {"text": "polled"}, "total_tokens": 5}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	h, err := NewWorkflowService(server.URL, "key").Start(t.Context(), schema.RunWorkflowRequest{Inputs: json.RawMessage(`{}`), User: "abc"})
+	require.NoError(t, err)
+	resp, err := h.Wait(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "run", resp.WorkflowRunID)
+	assert.Equal(t, "task", resp.TaskID)
+	assert.Equal(t, schema.WorkflowStatusSucceeded, resp.Data.Status)
+	assert.Equal(t, "polled", resp.Data.Outputs["text"])
+	assert.Equal(t, 5, resp.Data.TotalToken)
+	_, open := <-h.Progress()
+	assert.False(t, open)
+}
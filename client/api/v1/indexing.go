@@ -0,0 +1,159 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+const (
+	// defaultIndexingMinInterval is the default delay before the second indexing status request.
+	defaultIndexingMinInterval = 500 * time.Millisecond
+	// defaultIndexingMaxInterval is the default upper bound of the delay between indexing status requests.
+	defaultIndexingMaxInterval = 10 * time.Second
+	// indexingBackoffFactor is the factor applied to the polling delay while nothing changes.
+	indexingBackoffFactor = 2
+)
+
+// ErrBatchNotFound is returned by WaitForIndexing when a batch has no documents, because its ID is
+// wrong or expired, or its documents were deleted.
+var ErrBatchNotFound = errors.New("indexing batch not found")
+
+// IndexingWaitOptions configures WaitForIndexing.
+type IndexingWaitOptions struct {
+	// MinInterval is the initial delay between two status requests.
+	// Default: 500 milliseconds
+	MinInterval time.Duration
+	// MaxInterval bounds the delay, which doubles after each request that shows no progress.
+	// Default: 10 seconds
+	MaxInterval time.Duration
+	// OnProgress is called after every status request.
+	OnProgress func(IndexingProgress)
+}
+
+// IndexingProgress summarizes the indexing progress of a batch.
+type IndexingProgress struct {
+	Documents         []schema.IndexingStatus
+	FinishedDocuments int
+	CompletedSegments int
+	TotalSegments     int
+}
+
+// Done reports whether every document finished indexing, successfully or not. Paused documents
+// are not finished.
+func (p IndexingProgress) Done() bool {
+	return len(p.Documents) > 0 && p.FinishedDocuments == len(p.Documents)
+}
+
+// IndexingError is returned by WaitForIndexing when documents failed to index.
+type IndexingError struct {
+	Documents []schema.IndexingStatus
+}
+
+// Error implements the error interface.
+func (e *IndexingError) Error() string {
+	msgs := make([]string, 0, len(e.Documents))
+	for _, doc := range e.Documents {
+		msgs = append(msgs, doc.ID+": "+doc.Error)
+	}
+	return "indexing failed: " + strings.Join(msgs, "; ")
+}
+
+// GetIndexingStatus retrieves the indexing progress of the documents created in a batch.
+func (d *DatasetService) GetIndexingStatus(
+	ctx context.Context,
+	datasetID, batch string,
+) (schema.IndexingStatusResponse, error) {
+	var respData schema.IndexingStatusResponse
+	err := d.send(ctx, http.MethodGet, datasetPath(datasetID, "documents", batch, "indexing-status"), nil, nil, &respData)
+	if err != nil {
+		return schema.IndexingStatusResponse{}, err
+	}
+	return respData, nil
+}
+
+// WaitForIndexing polls the indexing status of a batch until every document is completed or failed,
+// or ctx is done. The delay between requests backs off while the progress does not change.
+// Documents that failed are reported in an *IndexingError along with the final statuses.
+// Paused documents keep the wait alive until they are resumed or ctx is done. A batch without
+// documents fails with ErrBatchNotFound.
+func (d *DatasetService) WaitForIndexing(
+	ctx context.Context,
+	datasetID, batch string,
+	opts IndexingWaitOptions,
+) ([]schema.IndexingStatus, error) {
+	minInterval, maxInterval := opts.MinInterval, opts.MaxInterval
+	if minInterval <= 0 {
+		minInterval = defaultIndexingMinInterval
+	}
+	if maxInterval < minInterval {
+		maxInterval = max(defaultIndexingMaxInterval, minInterval)
+	}
+
+	interval := minInterval
+	var last IndexingProgress
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return last.Documents, ctx.Err()
+		case <-timer.C:
+		}
+
+		resp, err := d.GetIndexingStatus(ctx, datasetID, batch)
+		if err != nil {
+			return last.Documents, err
+		}
+		if len(resp.Data) == 0 {
+			return last.Documents, ErrBatchNotFound
+		}
+		progress := newIndexingProgress(resp.Data)
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+		if progress.Done() {
+			return progress.Documents, indexingError(progress.Documents)
+		}
+
+		if progress.CompletedSegments != last.CompletedSegments || progress.FinishedDocuments != last.FinishedDocuments {
+			interval = minInterval
+		} else {
+			interval = min(interval*indexingBackoffFactor, maxInterval)
+		}
+		last = progress
+		timer.Reset(interval)
+	}
+}
+
+func newIndexingProgress(docs []schema.IndexingStatus) IndexingProgress {
+	progress := IndexingProgress{Documents: docs}
+	for _, doc := range docs {
+		progress.CompletedSegments += doc.CompletedSegments
+		progress.TotalSegments += doc.TotalSegments
+		if doc.IndexingStatus == schema.IndexingStatusCompleted || doc.IndexingStatus == schema.IndexingStatusError {
+			progress.FinishedDocuments++
+		}
+	}
+	return progress
+}
+
+func indexingError(docs []schema.IndexingStatus) error {
+	var failed []schema.IndexingStatus
+	for _, doc := range docs {
+		if doc.IndexingStatus == schema.IndexingStatusError {
+			failed = append(failed, doc)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &IndexingError{Documents: failed}
+}
@@ -0,0 +1,55 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForIndexing(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/datasets/ds/documents/batch/indexing-status", r.URL.Path)
+		calls++
+		status, completed := "indexing", calls
+		if calls == 3 {
+			status = "completed"
+		}
+		fmt.Fprintf(w, `{"data": [
+			{"id": "a", "indexing_status": "%s", "completed_segments": %d, "total_segments": 3},
+			{"id": "b", "indexing_status": "error", "error": "bad file"}
+		]}`, status, completed)
+	}))
+	defer server.Close()
+
+	var progress []int
+	docs, err := NewDatasetService(server.URL, "key").WaitForIndexing(t.Context(), "ds", "batch", IndexingWaitOptions{
+		MinInterval: time.Millisecond,
+		OnProgress:  func(p IndexingProgress) { progress = append(progress, p.CompletedSegments) },
+	})
+
+	var indexingErr *IndexingError
+	require.True(t, errors.As(err, &indexingErr))
+	assert.Equal(t, "b", indexingErr.Documents[0].ID)
+	assert.Len(t, docs, 2)
+	assert.Equal(t, []int{1, 2, 3}, progress)
+}
+
+func TestWaitForIndexingBatchNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	_, err := NewDatasetService(server.URL, "key").WaitForIndexing(t.Context(), "ds", "expired", IndexingWaitOptions{})
+	assert.ErrorIs(t, err, ErrBatchNotFound)
+}
@@ -0,0 +1,73 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// CreateMetadataField adds a custom metadata field to a dataset.
+func (d *DatasetService) CreateMetadataField(
+	ctx context.Context,
+	datasetID string,
+	req schema.CreateMetadataFieldRequest,
+) (schema.MetadataField, error) {
+	var respData schema.MetadataField
+	err := d.send(ctx, http.MethodPost, datasetPath(datasetID, "metadata"), req, nil, &respData)
+	if err != nil {
+		return schema.MetadataField{}, err
+	}
+	return respData, nil
+}
+
+// ListMetadataFields retrieves the metadata fields of a dataset and whether built-in fields are enabled.
+func (d *DatasetService) ListMetadataFields(
+	ctx context.Context,
+	datasetID string,
+) (schema.MetadataFieldListResponse, error) {
+	var respData schema.MetadataFieldListResponse
+	err := d.send(ctx, http.MethodGet, datasetPath(datasetID, "metadata"), nil, nil, &respData)
+	if err != nil {
+		return schema.MetadataFieldListResponse{}, err
+	}
+	return respData, nil
+}
+
+// RenameMetadataField renames a metadata field.
+func (d *DatasetService) RenameMetadataField(
+	ctx context.Context,
+	datasetID, metadataID, name string,
+) (schema.MetadataField, error) {
+	req := schema.RenameMetadataFieldRequest{Name: name}
+	var respData schema.MetadataField
+	err := d.send(ctx, http.MethodPatch, datasetPath(datasetID, "metadata", metadataID), req, nil, &respData)
+	if err != nil {
+		return schema.MetadataField{}, err
+	}
+	return respData, nil
+}
+
+// DeleteMetadataField deletes a metadata field and its values.
+func (d *DatasetService) DeleteMetadataField(ctx context.Context, datasetID, metadataID string) error {
+	return d.send(ctx, http.MethodDelete, datasetPath(datasetID, "metadata", metadataID), nil, nil, nil)
+}
+
+// SetBuiltInMetadata enables or disables the built-in metadata fields of a dataset, see
+// schema.BuiltInMetadataEnable and schema.BuiltInMetadataDisable.
+func (d *DatasetService) SetBuiltInMetadata(ctx context.Context, datasetID, action string) error {
+	return d.send(ctx, http.MethodPost, datasetPath(datasetID, "metadata", "built-in", action), nil, nil, nil)
+}
+
+// UpdateDocumentMetadata replaces the metadata values of documents.
+func (d *DatasetService) UpdateDocumentMetadata(
+	ctx context.Context,
+	datasetID string,
+	operations []schema.DocumentMetadataOperation,
+) error {
+	req := schema.UpdateDocumentMetadataRequest{OperationData: operations}
+	return d.send(ctx, http.MethodPost, datasetPath(datasetID, "documents", "metadata"), req, nil, nil)
+}
@@ -0,0 +1,81 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestMetadataService(t *testing.T) {
+	datasets := func(baseURL string) *DatasetService { return NewDatasetService(baseURL, "key") }
+	testEndpoints(t, []endpointTest{
+		{
+			name: "create field",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).CreateMetadataField(ctx, "ds", schema.CreateMetadataFieldRequest{Type: schema.MetadataTypeString, Name: "lang"})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/ds/metadata",
+			body:     `{"type": "string", "name": "lang"}`,
+			response: `{"id": "m", "name": "lang", "type": "string"}`,
+			want:     schema.MetadataField{ID: "m", Name: "lang", Type: schema.MetadataTypeString},
+		},
+		{
+			name: "list fields",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).ListMetadataFields(ctx, "ds")
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds/metadata",
+			response: `{"doc_metadata": [{"id": "m", "name": "lang", "type": "string", "count": 3}], "built_in_field_enabled": true}`,
+			want: schema.MetadataFieldListResponse{
+				DocMetadata:         []schema.MetadataField{{ID: "m", Name: "lang", Type: schema.MetadataTypeString, Count: 3}},
+				BuiltInFieldEnabled: true,
+			},
+		},
+		{
+			name: "rename field",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).RenameMetadataField(ctx, "ds", "m", "language")
+			},
+			method:   http.MethodPatch,
+			path:     "/v1/datasets/ds/metadata/m",
+			body:     `{"name": "language"}`,
+			response: `{"id": "m", "name": "language", "type": "string"}`,
+			want:     schema.MetadataField{ID: "m", Name: "language", Type: schema.MetadataTypeString},
+		},
+		{
+			name: "delete field",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).DeleteMetadataField(ctx, "ds", "m")
+			},
+			method: http.MethodDelete,
+			path:   "/v1/datasets/ds/metadata/m",
+		},
+		{
+			name: "built-in fields",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).SetBuiltInMetadata(ctx, "ds", schema.BuiltInMetadataDisable)
+			},
+			method: http.MethodPost,
+			path:   "/v1/datasets/ds/metadata/built-in/disable",
+		},
+		{
+			name: "document values",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).UpdateDocumentMetadata(ctx, "ds", []schema.DocumentMetadataOperation{{
+					DocumentID:   "doc",
+					MetadataList: []schema.MetadataValue{{ID: "m", Name: "lang", Value: "en"}},
+				}})
+			},
+			method: http.MethodPost,
+			path:   "/v1/datasets/ds/documents/metadata",
+			body:   `{"operation_data": [{"document_id": "doc", "metadata_list": [{"id": "m", "name": "lang", "value": "en"}]}]}`,
+		},
+	})
+}
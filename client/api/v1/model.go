@@ -0,0 +1,22 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// ListModels retrieves the model providers of the workspace with their models of the given type,
+// see schema.ModelTypeTextEmbedding and schema.ModelTypeRerank.
+func (d *DatasetService) ListModels(ctx context.Context, modelType string) ([]schema.ModelProvider, error) {
+	var respData schema.ModelListResponse
+	err := d.send(ctx, http.MethodGet, "v1/workspaces/current/models/model-types/"+modelType, nil, nil, &respData)
+	if err != nil {
+		return nil, err
+	}
+	return respData.Data, nil
+}
@@ -0,0 +1,25 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// Retrieve queries a dataset and returns the matching segments, without involving an app.
+func (d *DatasetService) Retrieve(
+	ctx context.Context,
+	datasetID string,
+	req schema.RetrieveRequest,
+) (schema.RetrieveResponse, error) {
+	var respData schema.RetrieveResponse
+	err := d.send(ctx, http.MethodPost, datasetPath(datasetID, "retrieve"), req, nil, &respData)
+	if err != nil {
+		return schema.RetrieveResponse{}, err
+	}
+	return respData, nil
+}
@@ -0,0 +1,73 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestRetrieve(t *testing.T) {
+	datasets := func(baseURL string) *DatasetService { return NewDatasetService(baseURL, "key") }
+	testEndpoints(t, []endpointTest{
+		{
+			name: "dataset settings",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).Retrieve(ctx, "ds", schema.RetrieveRequest{Query: "dify"})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/ds/retrieve",
+			body:     `{"query": "dify"}`,
+			response: `{"query": {"content": "dify"}, "records": []}`,
+			want:     schema.RetrieveResponse{Query: schema.RetrieveQuery{Content: "dify"}, Records: []schema.RetrievalRecord{}},
+		},
+		{
+			name: "metadata filter",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).Retrieve(ctx, "ds", schema.RetrieveRequest{
+					Query: "dify",
+					RetrievalModel: &schema.RetrievalModel{
+						SearchMethod: schema.SearchMethodHybrid,
+						TopK:         5,
+						MetadataFilteringConditions: &schema.MetadataFilteringConditions{
+							LogicalOperator: schema.LogicalOperatorAnd,
+							Conditions: []schema.MetadataCondition{
+								{Name: "lang", ComparisonOperator: schema.ComparisonIs, Value: "en"},
+								{Name: "author", ComparisonOperator: schema.ComparisonNotEmpty},
+							},
+						},
+					},
+				})
+			},
+			method: http.MethodPost,
+			path:   "/v1/datasets/ds/retrieve",
+			body: `{"query": "dify", "retrieval_model": {
+				"search_method": "hybrid_search", "reranking_enable": false, "top_k": 5,
+				"score_threshold_enabled": false, "score_threshold": 0,
+				"metadata_filtering_conditions": {"logical_operator": "and", "conditions": [
+					{"name": "lang", "comparison_operator": "is", "value": "en"},
+					{"name": "author", "comparison_operator": "not empty"}
+				]}}}`,
+			response: `{"query": {"content": "dify"}, "records": [{
+				"segment": {"id": "s", "content": "dify is", "document": {"id": "doc", "name": "faq.md"}},
+				"child_chunks": [{"id": "c", "content": "dify", "score": 0.8}],
+				"score": 0.9
+			}]}`,
+			want: schema.RetrieveResponse{
+				Query: schema.RetrieveQuery{Content: "dify"},
+				Records: []schema.RetrievalRecord{{
+					Segment: schema.RetrievedSegment{
+						Segment:  schema.Segment{ID: "s", Content: "dify is"},
+						Document: schema.RetrievedDocument{ID: "doc", Name: "faq.md"},
+					},
+					ChildChunks: []schema.RetrievedChildChunk{{ID: "c", Content: "dify", Score: 0.8}},
+					Score:       0.9,
+				}},
+			},
+		},
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// ListSegments retrieves a page of the segments of a document, optionally filtered by keyword and status.
+func (d *DatasetService) ListSegments(
+	ctx context.Context,
+	datasetID, documentID string,
+	query schema.SegmentListQuery,
+) (schema.SegmentListResponse, error) {
+	var respData schema.SegmentListResponse
+	err := d.send(ctx, http.MethodGet, segmentPath(datasetID, documentID), nil, query, &respData)
+	if err != nil {
+		return schema.SegmentListResponse{}, err
+	}
+	return respData, nil
+}
+
+// CreateSegments adds segments to a document.
+func (d *DatasetService) CreateSegments(
+	ctx context.Context,
+	datasetID, documentID string,
+	segments []schema.NewSegment,
+) (schema.CreateSegmentsResponse, error) {
+	req := schema.CreateSegmentsRequest{Segments: segments}
+	var respData schema.CreateSegmentsResponse
+	err := d.send(ctx, http.MethodPost, segmentPath(datasetID, documentID), req, nil, &respData)
+	if err != nil {
+		return schema.CreateSegmentsResponse{}, err
+	}
+	return respData, nil
+}
+
+// GetSegment retrieves a segment.
+func (d *DatasetService) GetSegment(
+	ctx context.Context,
+	datasetID, documentID, segmentID string,
+) (schema.SegmentResponse, error) {
+	var respData schema.SegmentResponse
+	err := d.send(ctx, http.MethodGet, segmentPath(datasetID, documentID, segmentID), nil, nil, &respData)
+	if err != nil {
+		return schema.SegmentResponse{}, err
+	}
+	return respData, nil
+}
+
+// UpdateSegment updates the content, answer, keywords or status of a segment.
+func (d *DatasetService) UpdateSegment(
+	ctx context.Context,
+	datasetID, documentID, segmentID string,
+	segment schema.SegmentUpdate,
+) (schema.SegmentResponse, error) {
+	req := schema.UpdateSegmentRequest{Segment: segment}
+	var respData schema.SegmentResponse
+	err := d.send(ctx, http.MethodPost, segmentPath(datasetID, documentID, segmentID), req, nil, &respData)
+	if err != nil {
+		return schema.SegmentResponse{}, err
+	}
+	return respData, nil
+}
+
+// DeleteSegment deletes a segment.
+func (d *DatasetService) DeleteSegment(ctx context.Context, datasetID, documentID, segmentID string) error {
+	return d.send(ctx, http.MethodDelete, segmentPath(datasetID, documentID, segmentID), nil, nil, nil)
+}
+
+// ListChildChunks retrieves a page of the child chunks of a segment.
+func (d *DatasetService) ListChildChunks(
+	ctx context.Context,
+	datasetID, documentID, segmentID string,
+	query schema.ChildChunkListQuery,
+) (schema.ChildChunkListResponse, error) {
+	var respData schema.ChildChunkListResponse
+	err := d.send(ctx, http.MethodGet, segmentPath(datasetID, documentID, segmentID, "child_chunks"), nil, query, &respData)
+	if err != nil {
+		return schema.ChildChunkListResponse{}, err
+	}
+	return respData, nil
+}
+
+// CreateChildChunk adds a child chunk to a segment.
+func (d *DatasetService) CreateChildChunk(
+	ctx context.Context,
+	datasetID, documentID, segmentID, content string,
+) (schema.ChildChunk, error) {
+	req := schema.ChildChunkRequest{Content: content}
+	var respData schema.ChildChunkResponse
+	err := d.send(ctx, http.MethodPost, segmentPath(datasetID, documentID, segmentID, "child_chunks"), req, nil, &respData)
+	if err != nil {
+		return schema.ChildChunk{}, err
+	}
+	return respData.Data, nil
+}
+
+// UpdateChildChunk replaces the content of a child chunk.
+func (d *DatasetService) UpdateChildChunk(
+	ctx context.Context,
+	datasetID, documentID, segmentID, childChunkID, content string,
+) (schema.ChildChunk, error) {
+	req := schema.ChildChunkRequest{Content: content}
+	var respData schema.ChildChunkResponse
+	err := d.send(ctx, http.MethodPatch, segmentPath(datasetID, documentID, segmentID, "child_chunks", childChunkID),
+		req, nil, &respData)
+	if err != nil {
+		return schema.ChildChunk{}, err
+	}
+	return respData.Data, nil
+}
+
+// DeleteChildChunk deletes a child chunk.
+func (d *DatasetService) DeleteChildChunk(
+	ctx context.Context,
+	datasetID, documentID, segmentID, childChunkID string,
+) error {
+	return d.send(ctx, http.MethodDelete, segmentPath(datasetID, documentID, segmentID, "child_chunks", childChunkID),
+		nil, nil, nil)
+}
+
+// segmentPath returns the API path of the segments of a document, joined with the optional sub-path elements.
+func segmentPath(datasetID, documentID string, elem ...string) string {
+	return datasetPath(datasetID, append([]string{"documents", documentID, "segments"}, elem...)...)
+}
@@ -0,0 +1,123 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestSegmentService(t *testing.T) {
+	datasets := func(baseURL string) *DatasetService { return NewDatasetService(baseURL, "key") }
+	enabled := false
+	testEndpoints(t, []endpointTest{
+		{
+			name: "list",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).ListSegments(ctx, "ds", "doc", schema.SegmentListQuery{Status: "completed", Limit: 20})
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds/documents/doc/segments",
+			query:    "status=completed&limit=20",
+			response: `{"data": [{"id": "s", "content": "hello", "keywords": ["hi"]}], "doc_form": "text_model", "limit": 20}`,
+			want: schema.SegmentListResponse{
+				Data:    []schema.Segment{{ID: "s", Content: "hello", Keywords: []string{"hi"}}},
+				DocForm: schema.DocFormText, Limit: 20,
+			},
+		},
+		{
+			name: "create",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).CreateSegments(ctx, "ds", "doc", []schema.NewSegment{{Content: "q", Answer: "a"}})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/ds/documents/doc/segments",
+			body:     `{"segments": [{"content": "q", "answer": "a"}]}`,
+			response: `{"data": [{"id": "s", "content": "q", "answer": "a"}], "doc_form": "qa_model"}`,
+			want: schema.CreateSegmentsResponse{
+				Data:    []schema.Segment{{ID: "s", Content: "q", Answer: "a"}},
+				DocForm: schema.DocFormQA,
+			},
+		},
+		{
+			name: "get",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).GetSegment(ctx, "ds", "doc", "s")
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds/documents/doc/segments/s",
+			response: `{"data": {"id": "s", "child_chunks": [{"id": "c", "content": "child"}]}, "doc_form": "hierarchical_model"}`,
+			want: schema.SegmentResponse{
+				Data:    schema.Segment{ID: "s", ChildChunks: []schema.ChildChunk{{ID: "c", Content: "child"}}},
+				DocForm: schema.DocFormHierarchical,
+			},
+		},
+		{
+			name: "update",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).UpdateSegment(ctx, "ds", "doc", "s", schema.SegmentUpdate{Content: "new", Enabled: &enabled})
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/ds/documents/doc/segments/s",
+			body:     `{"segment": {"content": "new", "enabled": false}}`,
+			response: `{"data": {"id": "s", "content": "new"}, "doc_form": "text_model"}`,
+			want:     schema.SegmentResponse{Data: schema.Segment{ID: "s", Content: "new"}, DocForm: schema.DocFormText},
+		},
+		{
+			name: "delete",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).DeleteSegment(ctx, "ds", "doc", "s")
+			},
+			method: http.MethodDelete,
+			path:   "/v1/datasets/ds/documents/doc/segments/s",
+		},
+		{
+			name: "list child chunks",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).ListChildChunks(ctx, "ds", "doc", "s", schema.ChildChunkListQuery{Keyword: "child", Page: 1})
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds/documents/doc/segments/s/child_chunks",
+			query:    "keyword=child&page=1",
+			response: `{"data": [{"id": "c", "segment_id": "s"}], "total": 1, "total_pages": 1, "page": 1}`,
+			want: schema.ChildChunkListResponse{
+				Data:  []schema.ChildChunk{{ID: "c", SegmentID: "s"}},
+				Total: 1, TotalPages: 1, Page: 1,
+			},
+		},
+		{
+			name: "create child chunk",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).CreateChildChunk(ctx, "ds", "doc", "s", "child")
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/ds/documents/doc/segments/s/child_chunks",
+			body:     `{"content": "child"}`,
+			response: `{"data": {"id": "c", "content": "child"}}`,
+			want:     schema.ChildChunk{ID: "c", Content: "child"},
+		},
+		{
+			name: "update child chunk",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).UpdateChildChunk(ctx, "ds", "doc", "s", "c", "changed")
+			},
+			method:   http.MethodPatch,
+			path:     "/v1/datasets/ds/documents/doc/segments/s/child_chunks/c",
+			body:     `{"content": "changed"}`,
+			response: `{"data": {"id": "c", "content": "changed"}}`,
+			want:     schema.ChildChunk{ID: "c", Content: "changed"},
+		},
+		{
+			name: "delete child chunk",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).DeleteChildChunk(ctx, "ds", "doc", "s", "c")
+			},
+			method: http.MethodDelete,
+			path:   "/v1/datasets/ds/documents/doc/segments/s/child_chunks/c",
+		},
+	})
+}
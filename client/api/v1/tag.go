@@ -0,0 +1,72 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// tagPath is the API path of the dataset tags of the workspace.
+const tagPath = "v1/datasets/tags"
+
+// CreateTag creates a dataset tag.
+func (d *DatasetService) CreateTag(ctx context.Context, name string) (schema.DatasetTag, error) {
+	var respData schema.DatasetTag
+	err := d.send(ctx, http.MethodPost, tagPath, schema.CreateTagRequest{Name: name}, nil, &respData)
+	if err != nil {
+		return schema.DatasetTag{}, err
+	}
+	return respData, nil
+}
+
+// ListTags retrieves the dataset tags of the workspace.
+func (d *DatasetService) ListTags(ctx context.Context) ([]schema.DatasetTag, error) {
+	var respData []schema.DatasetTag
+	err := d.send(ctx, http.MethodGet, tagPath, nil, nil, &respData)
+	if err != nil {
+		return nil, err
+	}
+	return respData, nil
+}
+
+// RenameTag renames a dataset tag.
+func (d *DatasetService) RenameTag(ctx context.Context, tagID, name string) (schema.DatasetTag, error) {
+	req := schema.RenameTagRequest{TagID: tagID, Name: name}
+	var respData schema.DatasetTag
+	err := d.send(ctx, http.MethodPatch, tagPath, req, nil, &respData)
+	if err != nil {
+		return schema.DatasetTag{}, err
+	}
+	return respData, nil
+}
+
+// DeleteTag deletes a dataset tag and detaches it from every dataset.
+func (d *DatasetService) DeleteTag(ctx context.Context, tagID string) error {
+	return d.send(ctx, http.MethodDelete, tagPath, schema.DeleteTagRequest{TagID: tagID}, nil, nil)
+}
+
+// BindTags attaches tags to a dataset.
+func (d *DatasetService) BindTags(ctx context.Context, datasetID string, tagIDs []string) error {
+	req := schema.BindTagsRequest{TagIDs: tagIDs, TargetID: datasetID}
+	return d.send(ctx, http.MethodPost, tagPath+"/binding", req, nil, nil)
+}
+
+// UnbindTag detaches a tag from a dataset.
+func (d *DatasetService) UnbindTag(ctx context.Context, datasetID, tagID string) error {
+	req := schema.UnbindTagRequest{TagID: tagID, TargetID: datasetID}
+	return d.send(ctx, http.MethodPost, tagPath+"/unbinding", req, nil, nil)
+}
+
+// GetDatasetTags retrieves the tags attached to a dataset.
+func (d *DatasetService) GetDatasetTags(ctx context.Context, datasetID string) (schema.DatasetTagsResponse, error) {
+	var respData schema.DatasetTagsResponse
+	err := d.send(ctx, http.MethodGet, datasetPath(datasetID, "tags"), nil, nil, &respData)
+	if err != nil {
+		return schema.DatasetTagsResponse{}, err
+	}
+	return respData, nil
+}
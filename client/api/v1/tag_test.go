@@ -0,0 +1,107 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestTagService(t *testing.T) {
+	datasets := func(baseURL string) *DatasetService { return NewDatasetService(baseURL, "key") }
+	testEndpoints(t, []endpointTest{
+		{
+			name: "create",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).CreateTag(ctx, "faq")
+			},
+			method:   http.MethodPost,
+			path:     "/v1/datasets/tags",
+			body:     `{"name": "faq"}`,
+			response: `{"id": "t", "name": "faq", "type": "knowledge", "binding_count": 0}`,
+			want:     schema.DatasetTag{ID: "t", Name: "faq", Type: "knowledge"},
+		},
+		{
+			name: "list",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).ListTags(ctx)
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/tags",
+			response: `[{"id": "t", "name": "faq", "binding_count": 2}]`,
+			want:     []schema.DatasetTag{{ID: "t", Name: "faq", BindingCount: 2}},
+		},
+		{
+			name: "rename",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).RenameTag(ctx, "t", "help")
+			},
+			method:   http.MethodPatch,
+			path:     "/v1/datasets/tags",
+			body:     `{"tag_id": "t", "name": "help"}`,
+			response: `{"id": "t", "name": "help"}`,
+			want:     schema.DatasetTag{ID: "t", Name: "help"},
+		},
+		{
+			name: "delete",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).DeleteTag(ctx, "t")
+			},
+			method: http.MethodDelete,
+			path:   "/v1/datasets/tags",
+			body:   `{"tag_id": "t"}`,
+		},
+		{
+			name: "bind",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).BindTags(ctx, "ds", []string{"t1", "t2"})
+			},
+			method: http.MethodPost,
+			path:   "/v1/datasets/tags/binding",
+			body:   `{"tag_ids": ["t1", "t2"], "target_id": "ds"}`,
+		},
+		{
+			name: "unbind",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return nil, datasets(baseURL).UnbindTag(ctx, "ds", "t1")
+			},
+			method: http.MethodPost,
+			path:   "/v1/datasets/tags/unbinding",
+			body:   `{"tag_id": "t1", "target_id": "ds"}`,
+		},
+		{
+			name: "dataset tags",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).GetDatasetTags(ctx, "ds")
+			},
+			method:   http.MethodGet,
+			path:     "/v1/datasets/ds/tags",
+			response: `{"data": [{"id": "t2", "name": "help"}], "total": 1}`,
+			want:     schema.DatasetTagsResponse{Data: []schema.DatasetTag{{ID: "t2", Name: "help"}}, Total: 1},
+		},
+		{
+			name: "models",
+			call: func(ctx context.Context, baseURL string) (any, error) {
+				return datasets(baseURL).ListModels(ctx, schema.ModelTypeTextEmbedding)
+			},
+			method: http.MethodGet,
+			path:   "/v1/workspaces/current/models/model-types/text-embedding",
+			response: `{"data": [{"provider": "openai", "label": {"en_US": "OpenAI"}, "status": "active",
+				"models": [{"model": "text-embedding-3-small", "model_type": "text-embedding", "status": "active"}]}]}`,
+			want: []schema.ModelProvider{{
+				Provider: "openai",
+				Label:    schema.I18nText{EnUS: "OpenAI"},
+				Status:   schema.ModelStatusActive,
+				Models: []schema.ProviderModel{{
+					Model:     "text-embedding-3-small",
+					ModelType: schema.ModelTypeTextEmbedding,
+					Status:    schema.ModelStatusActive,
+				}},
+			}},
+		},
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// ttsEventMarker is looked up before decoding an event, so that text events are passed through untouched.
+var ttsEventMarker = []byte(schema.EventTTSMessage)
+
+// ttsEvent holds the fields of tts_message and tts_message_end events.
+type ttsEvent struct {
+	Event string `json:"event"`
+	Audio string `json:"audio"`
+}
+
+// SplitAudio separates text to speech audio from a workflow or chat stream with auto-play enabled.
+// The returned sequence yields every event except tts_message and tts_message_end, while the base64
+// MP3 chunks of tts_message events are decoded into the returned AudioStream.
+//
+// Audio is produced as the returned sequence is consumed, so the sequence must be iterated while the
+// audio is read from another goroutine. The audio ends with io.EOF on tts_message_end, or with
+// io.ErrUnexpectedEOF when the stream ends or iteration stops before it.
+func SplitAudio(stream iter.Seq2[[]byte, error]) (iter.Seq2[[]byte, error], *AudioStream) {
+	audio := newAudioStream()
+	events := func(yield func([]byte, error) bool) {
+		defer audio.finish(io.ErrUnexpectedEOF)
+		for data, err := range stream {
+			if err != nil {
+				audio.finish(err)
+				yield(nil, err)
+				return
+			}
+			if bytes.Contains(data, ttsEventMarker) {
+				handled, err := audio.handle(data)
+				if err != nil {
+					audio.finish(err)
+					yield(nil, err)
+					return
+				}
+				if handled {
+					continue
+				}
+			}
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+	return events, audio
+}
+
+// AudioStream is the decoded audio of a stream split by SplitAudio. It buffers audio that has not
+// been read yet, so slow readers never block the text events.
+type AudioStream struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    bytes.Buffer
+	err    error // set once no more audio will be written
+	closed bool
+}
+
+func newAudioStream() *AudioStream {
+	s := &AudioStream{}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Read reads decoded audio, blocking until audio is available or the audio ends.
+func (s *AudioStream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.buf.Len() == 0 && s.err == nil && !s.closed {
+		s.cond.Wait()
+	}
+	switch {
+	case s.closed:
+		return 0, io.ErrClosedPipe
+	case s.buf.Len() > 0:
+		return s.buf.Read(p)
+	default:
+		return 0, s.err
+	}
+}
+
+// Close discards the remaining audio. Audio received afterwards is dropped.
+func (s *AudioStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.buf.Reset()
+	s.cond.Broadcast()
+	return nil
+}
+
+// handle decodes a tts event into the stream, reporting false for other events.
+func (s *AudioStream) handle(data []byte) (bool, error) {
+	var event ttsEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return false, fmt.Errorf("failed to decode stream event: %w", err)
+	}
+	switch event.Event {
+	case schema.EventTTSMessage:
+		chunk, err := base64.StdEncoding.DecodeString(event.Audio)
+		if err != nil {
+			return true, fmt.Errorf("failed to decode tts audio: %w", err)
+		}
+		s.write(chunk)
+		return true, nil
+	case schema.EventTTSMessageEnd:
+		s.finish(io.EOF)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func (s *AudioStream) write(p []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.err != nil {
+		return
+	}
+	s.buf.Write(p)
+	s.cond.Broadcast()
+}
+
+// finish ends the audio with err, unless it already ended.
+func (s *AudioStream) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = err
+	}
+	s.cond.Broadcast()
+}
@@ -0,0 +1,66 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"encoding/base64"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeStream(events ...string) func(yield func([]byte, error) bool) {
+	return func(yield func([]byte, error) bool) {
+		for _, e := range events {
+			if !yield([]byte(e), nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestSplitAudio(t *testing.T) {
+	chunk := func(s string) string {
+		return `{"event": "tts_message", "audio": "` + base64.StdEncoding.EncodeToString([]byte(s)) + `"}`
+	}
+
+	t.Run("demultiplex audio and text", func(t *testing.T) {
+		events, audio := SplitAudio(fakeStream(
+			`{"event": "text_chunk", "data": {"text": "hello"}}`,
+			chunk("mp3-1"),
+			`{"event": "text_chunk", "data": {"text": " world"}}`,
+			chunk("mp3-2"),
+			`{"event": "tts_message_end", "audio": ""}`,
+			`{"event": "workflow_finished", "data": {}}`,
+		))
+
+		done := make(chan []byte)
+		go func() {
+			data, err := io.ReadAll(audio)
+			assert.NoError(t, err)
+			done <- data
+		}()
+
+		var texts []string
+		for data, err := range events {
+			require.NoError(t, err)
+			texts = append(texts, string(slices.Clone(data)))
+		}
+		assert.Len(t, texts, 3)
+		assert.Equal(t, "mp3-1mp3-2", string(<-done))
+	})
+
+	t.Run("audio is cut short when the stream ends early", func(t *testing.T) {
+		events, audio := SplitAudio(fakeStream(chunk("mp3-1")))
+		for range events {
+			t.Fatal("no text events expected")
+		}
+		data, err := io.ReadAll(audio)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, "mp3-1", string(data))
+	})
+}
@@ -0,0 +1,246 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// TypedRunRequest is a workflow run request whose inputs are a Go value instead of raw JSON.
+type TypedRunRequest[In any] struct {
+	Inputs In
+	User   string
+	Files  []schema.RunWorkflowRequestFile
+}
+
+// TypedStreamEvent is a streaming workflow event. Outputs is only set on the workflow_finished event.
+type TypedStreamEvent[Out any] struct {
+	schema.WorkflowStreamEvent
+	Outputs *Out
+}
+
+// WorkflowRunError is returned when a workflow run finishes without succeeding.
+type WorkflowRunError struct {
+	RunID   string
+	Status  string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *WorkflowRunError) Error() string {
+	return fmt.Sprintf("workflow run %s %s: %s", e.RunID, e.Status, e.Message)
+}
+
+// OutputError is returned when workflow outputs cannot be decoded into the requested type.
+type OutputError struct {
+	Fields []FieldError
+}
+
+// Error implements the error interface.
+func (e *OutputError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return "invalid outputs: " + strings.Join(msgs, "; ")
+}
+
+// RunTyped marshals req.Inputs into the request inputs, executes the workflow in blocking mode
+// and decodes the outputs into Out. A run that does not succeed returns a *WorkflowRunError.
+func RunTyped[In, Out any](
+	ctx context.Context,
+	w *WorkflowService,
+	req TypedRunRequest[In],
+) (Out, schema.RunWorkflowResponse, error) {
+	var out Out
+	runReq, err := req.build(BlockingMode)
+	if err != nil {
+		return out, schema.RunWorkflowResponse{}, err
+	}
+	resp, err := w.Run(ctx, runReq)
+	if err != nil {
+		return out, resp, err
+	}
+	if resp.Data.Status != schema.WorkflowStatusSucceeded {
+		return out, resp, &WorkflowRunError{RunID: resp.WorkflowRunID, Status: resp.Data.Status, Message: resp.Data.Error}
+	}
+	out, err = DecodeOutputs[Out](resp.Data.Outputs)
+	return out, resp, err
+}
+
+// RunStreamTyped marshals req.Inputs into the request inputs and executes the workflow in streaming mode.
+// Every event is decoded; the workflow_finished event carries the outputs decoded into Out.
+// Error events and runs that do not succeed are yielded as errors.
+func RunStreamTyped[In, Out any](
+	ctx context.Context,
+	w *WorkflowService,
+	req TypedRunRequest[In],
+) (iter.Seq2[TypedStreamEvent[Out], error], error) {
+	runReq, err := req.build(StreamMode)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := w.RunStream(ctx, runReq)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(TypedStreamEvent[Out], error) bool) {
+		for data, err := range DecodeWorkflowEvents(stream) {
+			if err != nil {
+				yield(TypedStreamEvent[Out]{}, err)
+				return
+			}
+			event := TypedStreamEvent[Out]{WorkflowStreamEvent: data}
+			if data.Event == schema.EventWorkflowFinished {
+				out, err := decodeFinishedOutputs[Out](data)
+				if err != nil {
+					yield(event, err)
+					return
+				}
+				event.Outputs = &out
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// DecodeWorkflowEvents decodes the raw events of a streaming workflow run.
+// An error event sent by dify is yielded as an error.
+func DecodeWorkflowEvents(stream iter.Seq2[[]byte, error]) iter.Seq2[schema.WorkflowStreamEvent, error] {
+	return func(yield func(schema.WorkflowStreamEvent, error) bool) {
+		for data, err := range stream {
+			if err != nil {
+				yield(schema.WorkflowStreamEvent{}, err)
+				return
+			}
+			var event schema.WorkflowStreamEvent
+			if err = json.Unmarshal(data, &event); err != nil {
+				yield(schema.WorkflowStreamEvent{}, fmt.Errorf("failed to decode stream event: %w", err))
+				return
+			}
+			if event.Event == schema.EventError {
+				yield(event, fmt.Errorf("workflow stream error %d %s: %s", event.Status, event.Code, event.Message))
+				return
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DecodeOutputs decodes workflow outputs into Out. When Out is a struct every exported field is
+// checked, and missing or mistyped fields are reported together in an *OutputError. Fields tagged
+// omitempty and pointer fields are optional.
+func DecodeOutputs[Out any](outputs map[string]any) (Out, error) {
+	var out Out
+	data, err := json.Marshal(outputs)
+	if err != nil {
+		return out, fmt.Errorf("failed to marshal outputs: %w", err)
+	}
+
+	rt := reflect.TypeOf(out)
+	if rt != nil && rt.Kind() == reflect.Struct {
+		oerr := &OutputError{}
+		checkOutputFields(oerr, rt, outputs)
+		if len(oerr.Fields) > 0 {
+			return out, oerr
+		}
+	}
+
+	if err = json.Unmarshal(data, &out); err != nil {
+		return out, fmt.Errorf("failed to decode outputs: %w", err)
+	}
+	return out, nil
+}
+
+// build converts the typed request into a RunWorkflowRequest with the given response mode.
+func (r TypedRunRequest[In]) build(mode string) (schema.RunWorkflowRequest, error) {
+	inputs, err := json.Marshal(r.Inputs)
+	if err != nil {
+		return schema.RunWorkflowRequest{}, fmt.Errorf("failed to marshal inputs: %w", err)
+	}
+	return schema.RunWorkflowRequest{
+		Inputs:       inputs,
+		ResponseMode: mode,
+		User:         r.User,
+		Files:        r.Files,
+	}, nil
+}
+
+// decodeFinishedOutputs decodes the outputs carried by a workflow_finished event.
+func decodeFinishedOutputs[Out any](event schema.WorkflowStreamEvent) (Out, error) {
+	var out Out
+	var data schema.RunWorkflowResponseData
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return out, fmt.Errorf("failed to decode workflow_finished event: %w", err)
+	}
+	if data.Status != schema.WorkflowStatusSucceeded {
+		return out, &WorkflowRunError{RunID: event.WorkflowRunID, Status: data.Status, Message: data.Error}
+	}
+	return DecodeOutputs[Out](data.Outputs)
+}
+
+// checkOutputFields reports the fields of struct type rt that are missing from outputs or hold a value
+// that does not decode into the field type.
+func checkOutputFields(oerr *OutputError, rt reflect.Type, outputs map[string]any) {
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			checkOutputFields(oerr, field.Type, outputs)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		value, ok := lookupOutput(outputs, name)
+		if !ok || value == nil {
+			if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+				oerr.Fields = append(oerr.Fields, FieldError{Variable: name, Message: "is missing"})
+			}
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		if json.Unmarshal(raw, reflect.New(field.Type).Interface()) != nil {
+			oerr.Fields = append(oerr.Fields, FieldError{
+				Variable: name,
+				Message:  fmt.Sprintf("expected %s, got %T", field.Type, value),
+			})
+		}
+	}
+}
+
+// lookupOutput returns the output matching a field name the way encoding/json matches object keys:
+// the exact key first, then any key equal under case folding.
+func lookupOutput(outputs map[string]any, name string) (any, bool) {
+	if value, ok := outputs[name]; ok {
+		return value, true
+	}
+	for key, value := range outputs {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,54 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeOutputs(t *testing.T) {
+	type Summary struct {
+		Text  string   `json:"text"`
+		Score float64  `json:"score"`
+		Tags  []string `json:"tags,omitempty"`
+		Note  *string  `json:"note"`
+	}
+
+	t.Run("decode struct", func(t *testing.T) {
+		out, err := DecodeOutputs[Summary](map[string]any{"text": "ok", "score": 0.5, "tags": []any{"a"}})
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Text: "ok", Score: 0.5, Tags: []string{"a"}}, out)
+	})
+
+	t.Run("report missing and mistyped fields", func(t *testing.T) {
+		_, err := DecodeOutputs[Summary](map[string]any{"score": "high"})
+
+		var oerr *OutputError
+		require.True(t, errors.As(err, &oerr))
+		assert.Equal(t, []FieldError{
+			{Variable: "text", Message: "is missing"},
+			{Variable: "score", Message: "expected float64, got string"},
+		}, oerr.Fields)
+	})
+
+	t.Run("match untagged fields case-insensitively", func(t *testing.T) {
+		type Article struct {
+			Title string
+			Body  string
+		}
+		out, err := DecodeOutputs[Article](map[string]any{"title": "hello", "BODY": "world"})
+		require.NoError(t, err)
+		assert.Equal(t, Article{Title: "hello", Body: "world"}, out)
+	})
+
+	t.Run("decode map", func(t *testing.T) {
+		out, err := DecodeOutputs[map[string]string](map[string]any{"text": "ok"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"text": "ok"}, out)
+	})
+}
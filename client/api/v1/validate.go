@@ -0,0 +1,200 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// fileTypeCustom is the file type that restricts uploads by extension instead of category.
+const fileTypeCustom = "custom"
+
+// FieldError describes a single input variable that failed validation.
+type FieldError struct {
+	Variable string
+	Message  string
+}
+
+// Error implements the error interface.
+func (e FieldError) Error() string {
+	return e.Variable + ": " + e.Message
+}
+
+// ValidationError is returned when inputs do not satisfy the application's input form.
+// It collects every failing variable instead of stopping at the first one.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+	return "invalid inputs: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(variable, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Variable: variable, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateInputs checks inputs against the application's input form before they are sent to dify.
+// It reports missing required variables, mismatched types, unknown select options, values exceeding
+// max length and files violating the configured type, extension, count or transfer method.
+// Extensions are only checked for remote_url files: a local_file only carries the ID of an
+// uploaded file, whose name is unknown here.
+// The returned error is a *ValidationError when any variable is invalid.
+func ValidateInputs(params schema.ApplicationParameters, inputs json.RawMessage) error {
+	vars, err := params.InputVariables()
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]json.RawMessage)
+	if len(bytes.TrimSpace(inputs)) > 0 {
+		if err = json.Unmarshal(inputs, &values); err != nil {
+			return fmt.Errorf("inputs must be a JSON object: %w", err)
+		}
+	}
+
+	verr := &ValidationError{}
+	for _, v := range vars {
+		raw, ok := values[v.Variable]
+		if !ok || isEmptyInput(raw) {
+			if v.Required {
+				verr.add(v.Variable, "is required")
+			}
+			continue
+		}
+		validateInput(verr, v, raw)
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// isEmptyInput reports whether a raw input value counts as not provided.
+func isEmptyInput(raw json.RawMessage) bool {
+	s := string(bytes.TrimSpace(raw))
+	return s == "" || s == "null" || s == `""` || s == "[]"
+}
+
+func validateInput(verr *ValidationError, v schema.InputVariable, raw json.RawMessage) {
+	switch v.Type {
+	case schema.InputTypeTextInput, schema.InputTypeParagraph:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			verr.add(v.Variable, "must be a string")
+			return
+		}
+		if v.MaxLength > 0 && utf8.RuneCountInString(s) > v.MaxLength {
+			verr.add(v.Variable, "must be at most %d characters", v.MaxLength)
+		}
+	case schema.InputTypeSelect:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			verr.add(v.Variable, "must be a string")
+			return
+		}
+		if !slices.Contains(v.Options, s) {
+			verr.add(v.Variable, "must be one of %v", v.Options)
+		}
+	case schema.InputTypeNumber:
+		if !isNumberInput(raw) {
+			verr.add(v.Variable, "must be a number")
+		}
+	case schema.InputTypeCheckbox:
+		var b bool
+		if json.Unmarshal(raw, &b) != nil {
+			verr.add(v.Variable, "must be a boolean")
+		}
+	case schema.InputTypeFile:
+		var f schema.RunWorkflowRequestFile
+		if json.Unmarshal(raw, &f) != nil {
+			verr.add(v.Variable, "must be a file object")
+			return
+		}
+		validateFile(verr, v, v.Variable, f)
+	case schema.InputTypeFileList:
+		var files []schema.RunWorkflowRequestFile
+		if json.Unmarshal(raw, &files) != nil {
+			verr.add(v.Variable, "must be a list of file objects")
+			return
+		}
+		if v.MaxLength > 0 && len(files) > v.MaxLength {
+			verr.add(v.Variable, "must contain at most %d files", v.MaxLength)
+		}
+		for i, f := range files {
+			validateFile(verr, v, fmt.Sprintf("%s[%d]", v.Variable, i), f)
+		}
+	}
+}
+
+// isNumberInput accepts JSON numbers as well as numeric strings, as dify does.
+func isNumberInput(raw json.RawMessage) bool {
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return true
+	}
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func validateFile(verr *ValidationError, v schema.InputVariable, name string, f schema.RunWorkflowRequestFile) {
+	if len(v.AllowedFileUploadMethods) > 0 && !slices.Contains(v.AllowedFileUploadMethods, f.TransferMethod) {
+		verr.add(name, "transfer method %q is not allowed, expected one of %v", f.TransferMethod, v.AllowedFileUploadMethods)
+	}
+	switch f.TransferMethod {
+	case "local_file":
+		if f.UploadFileID == "" {
+			verr.add(name, "upload_file_id is required for local_file")
+		}
+	case "remote_url":
+		if f.URL == "" {
+			verr.add(name, "url is required for remote_url")
+		}
+	}
+
+	if len(v.AllowedFileTypes) == 0 {
+		return
+	}
+	if !slices.Contains(v.AllowedFileTypes, f.Type) {
+		verr.add(name, "file type %q is not allowed, expected one of %v", f.Type, v.AllowedFileTypes)
+		return
+	}
+	// The extension of a local_file is unknown, since only the ID of the upload is sent.
+	if f.Type != fileTypeCustom || len(v.AllowedFileExtensions) == 0 || f.TransferMethod != "remote_url" {
+		return
+	}
+	ext := fileExtension(f.URL)
+	if !slices.ContainsFunc(v.AllowedFileExtensions, func(e string) bool { return strings.EqualFold(e, ext) }) {
+		verr.add(name, "file extension %q is not allowed, expected one of %v", ext, v.AllowedFileExtensions)
+	}
+}
+
+// fileExtension returns the extension of the file referenced by rawURL, including the leading dot.
+func fileExtension(rawURL string) string {
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+	return path.Ext(p)
+}
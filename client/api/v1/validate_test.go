@@ -0,0 +1,99 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestValidateInputs(t *testing.T) {
+	params := schema.ApplicationParameters{
+		UserInputForm: []map[string]any{
+			{"text-input": map[string]any{"variable": "name", "required": true, "max_length": 5}},
+			{"select": map[string]any{"variable": "lang", "options": []string{"en", "zh"}}},
+			{"number": map[string]any{"variable": "count"}},
+			{"file-list": map[string]any{
+				"variable":                    "docs",
+				"max_length":                  1,
+				"allowed_file_types":          []string{"custom"},
+				"allowed_file_extensions":     []string{".pdf"},
+				"allowed_file_upload_methods": []string{"remote_url"},
+			}},
+		},
+	}
+
+	t.Run("valid inputs", func(t *testing.T) {
+		inputs := json.RawMessage(`{
+			"name": "dify",
+			"lang": "en",
+			"count": "3",
+			"docs": [{"type": "custom", "transfer_method": "remote_url", "url": "https://example.com/a.PDF"}]
+		}`)
+		require.NoError(t, ValidateInputs(params, inputs))
+	})
+
+	t.Run("optional inputs may be omitted", func(t *testing.T) {
+		require.NoError(t, ValidateInputs(params, json.RawMessage(`{"name": "dify"}`)))
+	})
+
+	t.Run("collects every invalid field", func(t *testing.T) {
+		inputs := json.RawMessage(`{
+			"lang": "fr",
+			"count": "three",
+			"docs": [
+				{"type": "custom", "transfer_method": "local_file", "upload_file_id": "1"},
+				{"type": "custom", "transfer_method": "remote_url", "url": "https://example.com/a.txt"}
+			]
+		}`)
+		err := ValidateInputs(params, inputs)
+
+		var verr *ValidationError
+		require.True(t, errors.As(err, &verr))
+		var vars []string
+		for _, f := range verr.Fields {
+			vars = append(vars, f.Variable)
+		}
+		assert.Equal(t, []string{"name", "lang", "count", "docs", "docs[0]", "docs[1]"}, vars)
+	})
+
+	t.Run("extensions are only checked for remote urls", func(t *testing.T) {
+		params := schema.ApplicationParameters{
+			UserInputForm: []map[string]any{
+				{"file": map[string]any{
+					"variable":                "doc",
+					"allowed_file_types":      []string{"custom"},
+					"allowed_file_extensions": []string{".pdf"},
+				}},
+			},
+		}
+		inputs := json.RawMessage(`{"doc": {"type": "custom", "transfer_method": "local_file", "upload_file_id": "1"}}`)
+		require.NoError(t, ValidateInputs(params, inputs))
+
+		inputs = json.RawMessage(`{"doc": {"type": "custom", "transfer_method": "remote_url", "url": "https://example.com/a.txt"}}`)
+		var verr *ValidationError
+		require.ErrorAs(t, ValidateInputs(params, inputs), &verr)
+		assert.Equal(t, "doc", verr.Fields[0].Variable)
+	})
+
+	t.Run("text exceeding max length", func(t *testing.T) {
+		err := ValidateInputs(params, json.RawMessage(`{"name": "dify-go"}`))
+		var verr *ValidationError
+		require.True(t, errors.As(err, &verr))
+		assert.Equal(t, "must be at most 5 characters", verr.Fields[0].Message)
+	})
+
+	t.Run("inputs must be an object", func(t *testing.T) {
+		err := ValidateInputs(params, json.RawMessage(`[]`))
+		require.Error(t, err)
+		var verr *ValidationError
+		assert.False(t, errors.As(err, &verr))
+	})
+}
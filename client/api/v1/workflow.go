@@ -9,6 +9,8 @@ import (
 	"errors"
 	"iter"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/yeeaiclub/dify-go/internal/handler"
 	"github.com/yeeaiclub/dify-go/schema"
@@ -24,16 +26,33 @@ const (
 // WorkflowService represents a client for interacting with the workflow API endpoints.
 type WorkflowService struct {
 	*BaseClient
+	params *parametersCache // cached app parameters, nil when input validation is disabled
+}
+
+// WorkflowOption defines a functional option for configuring the WorkflowService.
+type WorkflowOption func(w *WorkflowService)
+
+// WithInputValidation validates request inputs against the app's parameters before every run,
+// so invalid inputs fail locally instead of after a round trip. The parameters are fetched on
+// first use and cached for ttl; a zero ttl caches them for the lifetime of the service.
+func WithInputValidation(ttl time.Duration) WorkflowOption {
+	return func(w *WorkflowService) {
+		w.params = &parametersCache{app: &Application{w.BaseClient}, ttl: ttl}
+	}
 }
 
 // NewWorkflowService creates a new Workflow client instance.
-func NewWorkflowService(baseURL, apiKey string) *WorkflowService {
+func NewWorkflowService(baseURL, apiKey string, opts ...WorkflowOption) *WorkflowService {
 	baseClient := &BaseClient{
 		client:  handler.NewClient(),
 		apiKey:  apiKey,
 		baseURL: baseURL,
 	}
-	return &WorkflowService{baseClient}
+	w := &WorkflowService{BaseClient: baseClient}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
 }
 
 // RunStream executes a workflow in streaming mode. Cannot execute if there is no published workflow.
@@ -44,6 +63,9 @@ func (w *WorkflowService) RunStream(
 	if req.ResponseMode != StreamMode {
 		return nil, errors.New("invalid response mode")
 	}
+	if err := w.validateInputs(ctx, req.Inputs); err != nil {
+		return nil, err
+	}
 
 	r, err := handler.NewRequestBuilder().
 		BaseURL(w.baseURL).
@@ -67,6 +89,9 @@ func (w *WorkflowService) Run(
 	if req.ResponseMode != BlockingMode {
 		return schema.RunWorkflowResponse{}, errors.New("response mode must be blocking")
 	}
+	if err := w.validateInputs(ctx, req.Inputs); err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
 
 	r, err := handler.NewRequestBuilder().
 		BaseURL(w.baseURL).
@@ -82,6 +107,9 @@ func (w *WorkflowService) Run(
 	if err != nil {
 		return schema.RunWorkflowResponse{}, err
 	}
+	if err = checkResponse(resp); err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
 	var respData schema.RunWorkflowResponse
 	err = json.Unmarshal(resp.Body, &respData)
 	if err != nil {
@@ -118,3 +146,57 @@ func (w *WorkflowService) GetLogs(
 	}
 	return respData, nil
 }
+
+// GetRun retrieves the status and results of a workflow run.
+func (w *WorkflowService) GetRun(ctx context.Context, runID string) (schema.WorkflowRun, error) {
+	var respData schema.WorkflowRun
+	err := w.send(ctx, http.MethodGet, "v1/workflows/run/"+runID, nil, nil, &respData)
+	if err != nil {
+		return schema.WorkflowRun{}, err
+	}
+	return respData, nil
+}
+
+// Stop stops a streaming workflow run. It only applies to runs in streaming mode.
+func (w *WorkflowService) Stop(ctx context.Context, taskID, user string) error {
+	req := schema.StopWorkflowRequest{User: user}
+	return w.send(ctx, http.MethodPost, "v1/workflows/tasks/"+taskID+"/stop", req, nil, nil)
+}
+
+// validateInputs checks inputs against the cached app parameters when input validation is enabled.
+func (w *WorkflowService) validateInputs(ctx context.Context, inputs json.RawMessage) error {
+	if w.params == nil {
+		return nil
+	}
+	params, err := w.params.get(ctx)
+	if err != nil {
+		return err
+	}
+	return ValidateInputs(params, inputs)
+}
+
+// parametersCache caches the application parameters used for input validation.
+type parametersCache struct {
+	app       *Application
+	ttl       time.Duration
+	mu        sync.Mutex
+	params    schema.ApplicationParameters
+	fetchedAt time.Time
+}
+
+// get returns the cached parameters, refreshing them when they are missing or expired.
+func (c *parametersCache) get(ctx context.Context) (schema.ApplicationParameters, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fetchedAt.IsZero() && (c.ttl <= 0 || time.Since(c.fetchedAt) < c.ttl) {
+		return c.params, nil
+	}
+	params, err := c.app.GetParameters(ctx)
+	if err != nil {
+		return schema.ApplicationParameters{}, err
+	}
+	c.params = params
+	c.fetchedAt = time.Now()
+	return params, nil
+}
@@ -0,0 +1,89 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command dify-dsl lints and diffs exported dify app DSL files.
+//
+// Usage:
+//
+//	dify-dsl lint app.yml [more.yml ...]
+//	dify-dsl diff old.yml new.yml
+//
+// lint exits with status 1 when any file has errors; warnings are printed but do not fail.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/yeeaiclub/dify-go/dsl"
+)
+
+// diffArgs is the number of files compared by the diff subcommand.
+const diffArgs = 2
+
+const usage = `usage:
+  dify-dsl lint app.yml [more.yml ...]
+  dify-dsl diff old.yml new.yml`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "lint":
+		err = lint(args)
+	case "diff":
+		err = diff(args)
+	default:
+		err = fmt.Errorf("unknown command %q\n%s", cmd, usage)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dify-dsl:", err)
+		os.Exit(1)
+	}
+}
+
+func lint(files []string) error {
+	if len(files) == 0 {
+		return errors.New("lint needs at least one file")
+	}
+	var failed int
+	for _, name := range files {
+		doc, err := dsl.ParseFile(name)
+		if err != nil {
+			return err
+		}
+		for _, issue := range dsl.Lint(doc) {
+			fmt.Printf("%s: %s\n", name, issue)
+			if issue.Severity == dsl.SeverityError {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d errors found", failed)
+	}
+	return nil
+}
+
+func diff(files []string) error {
+	if len(files) != diffArgs {
+		return errors.New("diff needs exactly two files")
+	}
+	old, err := dsl.ParseFile(files[0])
+	if err != nil {
+		return err
+	}
+	updated, err := dsl.ParseFile(files[1])
+	if err != nil {
+		return err
+	}
+	for _, change := range dsl.Diff(old, updated) {
+		fmt.Println(change)
+	}
+	return nil
+}
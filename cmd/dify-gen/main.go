@@ -0,0 +1,120 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command dify-gen generates a typed Go client bound to a single dify app.
+//
+// The app is described either by a live app (-base-url and -api-key, or the DIFY_API_KEY
+// environment variable), a saved parameters response (-params) or an exported app DSL file (-dsl).
+// It is intended to be used with go generate:
+//
+//	//go:generate go run github.com/yeeaiclub/dify-go/cmd/dify-gen -dsl summarizer.yml -pkg summarizer -out client_gen.go
+//
+// Regenerating an unchanged app leaves the output untouched. When the app's form changed in a way
+// that breaks existing callers, the changes are reported and the file is only rewritten with -allow-breaking.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/internal/codegen"
+)
+
+// outputPerm is the permission used for the generated file.
+const outputPerm = 0o644
+
+type options struct {
+	pkg           string
+	out           string
+	dslFile       string
+	paramsFile    string
+	baseURL       string
+	apiKey        string
+	allowBreaking bool
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.pkg, "pkg", "", "package name of the generated client (required)")
+	flag.StringVar(&opts.out, "out", "client_gen.go", "output file")
+	flag.StringVar(&opts.dslFile, "dsl", "", "exported app DSL file")
+	flag.StringVar(&opts.paramsFile, "params", "", "saved response of the app parameters endpoint")
+	flag.StringVar(&opts.baseURL, "base-url", "", "dify API base URL, used to fetch the parameters of a live app")
+	flag.StringVar(&opts.apiKey, "api-key", os.Getenv("DIFY_API_KEY"), "app API key, defaults to $DIFY_API_KEY")
+	flag.BoolVar(&opts.allowBreaking, "allow-breaking", false, "rewrite the client even if the app changed incompatibly")
+	flag.Parse()
+
+	if err := run(context.Background(), opts); err != nil {
+		fmt.Fprintln(os.Stderr, "dify-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options) error {
+	if opts.pkg == "" {
+		return errors.New("-pkg is required")
+	}
+	spec, err := loadSpec(ctx, opts)
+	if err != nil {
+		return err
+	}
+	src, err := codegen.Generate(spec)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(opts.out)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if bytes.Equal(existing, src) {
+		return nil
+	}
+	if old, ok, err := codegen.ReadSignature(existing); err != nil {
+		return err
+	} else if ok {
+		if changes := codegen.Breaking(old, spec); len(changes) > 0 {
+			for _, c := range changes {
+				fmt.Fprintln(os.Stderr, "breaking change:", c)
+			}
+			if !opts.allowBreaking {
+				return fmt.Errorf("%d breaking changes in %s, rerun with -allow-breaking to accept them", len(changes), opts.out)
+			}
+		}
+	}
+	return os.WriteFile(opts.out, src, outputPerm)
+}
+
+func loadSpec(ctx context.Context, opts options) (codegen.Spec, error) {
+	switch {
+	case opts.dslFile != "":
+		data, err := os.ReadFile(opts.dslFile)
+		if err != nil {
+			return codegen.Spec{}, err
+		}
+		return codegen.SpecFromDSL(opts.pkg, data)
+	case opts.paramsFile != "":
+		data, err := os.ReadFile(opts.paramsFile)
+		if err != nil {
+			return codegen.Spec{}, err
+		}
+		return codegen.SpecFromParametersJSON(opts.pkg, data)
+	case opts.baseURL != "":
+		if opts.apiKey == "" {
+			return codegen.Spec{}, errors.New("-api-key or DIFY_API_KEY is required with -base-url")
+		}
+		params, err := v1.NewApplication(opts.baseURL, opts.apiKey).GetParameters(ctx)
+		if err != nil {
+			return codegen.Spec{}, err
+		}
+		return codegen.SpecFromParameters(opts.pkg, params)
+	default:
+		return codegen.Spec{}, errors.New("one of -dsl, -params or -base-url is required")
+	}
+}
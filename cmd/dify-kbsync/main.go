@@ -0,0 +1,96 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command dify-kbsync mirrors a local directory into a dify knowledge base.
+//
+// Usage:
+//
+//	dify-kbsync -base-url https://api.dify.ai -dataset <id> [-include '**/*.md'] [-exclude 'drafts/**'] [-prune] [-dry-run] dir
+//
+// The dataset API key is read from -api-key or the DIFY_DATASET_API_KEY environment variable.
+// Files are tracked by metadata fields, so running the command again only uploads changed files.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/kbsync"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// patterns is a flag that can be repeated.
+type patterns []string
+
+func (p *patterns) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patterns) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+type options struct {
+	baseURL           string
+	apiKey            string
+	datasetID         string
+	indexingTechnique string
+	sync              kbsync.Options
+	noWait            bool
+}
+
+func main() {
+	var opts options
+	var include, exclude patterns
+	flag.StringVar(&opts.baseURL, "base-url", "", "dify API base URL (required)")
+	flag.StringVar(&opts.apiKey, "api-key", os.Getenv("DIFY_DATASET_API_KEY"),
+		"dataset API key, defaults to $DIFY_DATASET_API_KEY")
+	flag.StringVar(&opts.datasetID, "dataset", "", "ID of the knowledge base (required)")
+	flag.Var(&include, "include", "only sync files matching the pattern; can be repeated")
+	flag.Var(&exclude, "exclude", "skip files matching the pattern; can be repeated")
+	flag.BoolVar(&opts.sync.Prune, "prune", false, "delete documents whose file no longer exists")
+	flag.BoolVar(&opts.sync.DryRun, "dry-run", false, "print the changes without applying them")
+	flag.IntVar(&opts.sync.Concurrency, "concurrency", 0, "maximum number of concurrent uploads")
+	flag.StringVar(&opts.indexingTechnique, "indexing-technique", schema.IndexingTechniqueHighQuality,
+		"indexing technique of created documents")
+	flag.BoolVar(&opts.noWait, "no-wait", false, "do not wait for the documents to be indexed")
+	flag.Parse()
+	opts.sync.Include, opts.sync.Exclude = include, exclude
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, opts, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "dify-kbsync:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options, args []string) error {
+	if len(args) != 1 {
+		return errors.New("exactly one directory is required")
+	}
+	if opts.baseURL == "" || opts.apiKey == "" || opts.datasetID == "" {
+		return errors.New("-base-url, -api-key and -dataset are required")
+	}
+	opts.sync.Settings.IndexingTechnique = opts.indexingTechnique
+	opts.sync.Wait = !opts.noWait
+
+	client := v1.NewDatasetService(opts.baseURL, opts.apiKey)
+	report, err := kbsync.Sync(ctx, client, opts.datasetID, args[0], opts.sync)
+	if report != nil {
+		if _, werr := report.WriteTo(os.Stdout); werr != nil {
+			return werr
+		}
+	}
+	if err != nil && report != nil {
+		return fmt.Errorf("%d operations failed", len(report.Failed()))
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command dify-wftest runs workflow regression suites against a published workflow.
+//
+// Usage:
+//
+//	dify-wftest -base-url https://api.dify.ai [-format text|json|junit] [-run regexp] suite.yml [more.yml ...]
+//
+// The app API key is read from -api-key or the DIFY_API_KEY environment variable. The command exits
+// with status 1 when any case fails. See package workflowtest for the suite format.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/workflowtest"
+)
+
+// Output formats.
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatJUnit = "junit"
+)
+
+// errFailed is returned when a case did not pass; the report already describes it.
+var errFailed = errors.New("some cases failed")
+
+type options struct {
+	baseURL     string
+	apiKey      string
+	format      string
+	out         string
+	run         string
+	concurrency int
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.baseURL, "base-url", "", "dify API base URL (required)")
+	flag.StringVar(&opts.apiKey, "api-key", os.Getenv("DIFY_API_KEY"), "app API key, defaults to $DIFY_API_KEY")
+	flag.StringVar(&opts.format, "format", formatText, "report format: text, json or junit")
+	flag.StringVar(&opts.out, "out", "", "report file, defaults to the standard output")
+	flag.StringVar(&opts.run, "run", "", "only run the cases whose name matches the regular expression")
+	flag.IntVar(&opts.concurrency, "concurrency", 0, "maximum number of concurrent runs")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, opts, flag.Args()); err != nil {
+		if !errors.Is(err, errFailed) {
+			fmt.Fprintln(os.Stderr, "dify-wftest:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options, files []string) error {
+	if len(files) == 0 {
+		return errors.New("at least one suite file is required")
+	}
+	if opts.baseURL == "" || opts.apiKey == "" {
+		return errors.New("-base-url and -api-key are required")
+	}
+	if opts.format != formatText && opts.format != formatJSON && opts.format != formatJUnit {
+		return fmt.Errorf("unknown format %q", opts.format)
+	}
+	runOpts := workflowtest.Options{Concurrency: opts.concurrency}
+	if opts.run != "" {
+		re, err := regexp.Compile(opts.run)
+		if err != nil {
+			return err
+		}
+		runOpts.Filter = re.MatchString
+	}
+
+	suites := make([]*workflowtest.Suite, 0, len(files))
+	for _, name := range files {
+		suite, err := workflowtest.LoadSuite(name)
+		if err != nil {
+			return err
+		}
+		suites = append(suites, suite)
+	}
+
+	runner := v1.NewWorkflowService(opts.baseURL, opts.apiKey)
+	reports := make([]*workflowtest.Report, 0, len(suites))
+	passed := true
+	for _, suite := range suites {
+		report := workflowtest.Run(ctx, runner, suite, runOpts)
+		reports = append(reports, report)
+		passed = passed && report.Passed()
+	}
+
+	w := io.Writer(os.Stdout)
+	if opts.out != "" {
+		f, err := os.Create(opts.out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(w, opts.format, reports); err != nil {
+		return err
+	}
+	if !passed {
+		return errFailed
+	}
+	return nil
+}
+
+func write(w io.Writer, format string, reports []*workflowtest.Report) error {
+	if format == formatJUnit {
+		return workflowtest.WriteJUnit(w, reports...)
+	}
+	for _, report := range reports {
+		var err error
+		if format == formatJSON {
+			err = report.WriteJSON(w)
+		} else {
+			err = report.WriteText(w)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxValueLen is the number of characters at which rendered values are truncated in a diff.
+const maxValueLen = 120
+
+// uiKeys are node configuration keys that only reflect the editor state.
+var uiKeys = map[string]bool{"selected": true, "width": true, "height": true}
+
+// ChangeKind describes how an element changed between two versions.
+type ChangeKind string
+
+// Change kinds.
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Change is a single semantic difference between two versions of an app.
+type Change struct {
+	Kind ChangeKind
+	// Path identifies the changed element, e.g. "nodes.llm.model" or "inputs.query".
+	Path string
+	Old  string
+	New  string
+}
+
+// String renders the change as a line suitable for code review.
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, c.Old, c.New)
+	}
+}
+
+// Diff returns the semantic differences from old to updated: app metadata, input, environment and
+// conversation variables, nodes and their configuration, edges, model settings and dependencies.
+// Editor-only state such as node positions is ignored.
+func Diff(old, updated *Document) []Change {
+	d := &differ{}
+	d.value("app.name", old.App.Name, updated.App.Name)
+	d.value("app.mode", old.App.Mode, updated.App.Mode)
+	d.value("app.description", old.App.Description, updated.App.Description)
+	d.value("version", old.Version, updated.Version)
+
+	oldStart, _ := old.StartNode()
+	newStart, _ := updated.StartNode()
+	diffKeyed(d, "inputs", oldStart.Data.Variables, newStart.Data.Variables,
+		func(v InputVariable) string { return v.Variable }, inputSummary)
+
+	oldWorkflow, newWorkflow := workflowOf(old), workflowOf(updated)
+	diffKeyed(d, "env", oldWorkflow.EnvironmentVariables, newWorkflow.EnvironmentVariables,
+		func(v Variable) string { return v.Name }, variableSummary)
+	diffKeyed(d, "conversation", oldWorkflow.ConversationVariables, newWorkflow.ConversationVariables,
+		func(v Variable) string { return v.Name }, variableSummary)
+
+	d.nodes(old.Nodes(), updated.Nodes())
+	diffKeyed(d, "edges", oldWorkflow.Graph.Edges, newWorkflow.Graph.Edges, edgeKey, edgeKey)
+
+	var oldModel, newModel *Model
+	if old.ModelConfig != nil {
+		oldModel = old.ModelConfig.Model
+	}
+	if updated.ModelConfig != nil {
+		newModel = updated.ModelConfig.Model
+	}
+	d.model("model_config.model", oldModel, newModel)
+	d.prePrompt(old.ModelConfig, updated.ModelConfig)
+
+	diffKeyed(d, "dependencies", old.Dependencies, updated.Dependencies,
+		func(dep Dependency) string { return dep.CurrentIdentifier },
+		func(dep Dependency) string { return dep.Type })
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(kind ChangeKind, path, oldValue, newValue string) {
+	d.changes = append(d.changes, Change{Kind: kind, Path: path, Old: oldValue, New: newValue})
+}
+
+// value records a modification when two scalar values differ.
+func (d *differ) value(path, oldValue, newValue string) {
+	if oldValue != newValue {
+		d.add(Modified, path, truncate(oldValue), truncate(newValue))
+	}
+}
+
+func (d *differ) model(path string, oldModel, newModel *Model) {
+	oldName, newName := modelName(oldModel), modelName(newModel)
+	switch {
+	case oldName == newName:
+	case oldName == "":
+		d.add(Added, path, "", newName)
+	case newName == "":
+		d.add(Removed, path, oldName, "")
+	default:
+		d.add(Modified, path, oldName, newName)
+	}
+}
+
+func (d *differ) prePrompt(oldConfig, newConfig *ModelConfig) {
+	var oldPrompt, newPrompt string
+	if oldConfig != nil {
+		oldPrompt = oldConfig.PrePrompt
+	}
+	if newConfig != nil {
+		newPrompt = newConfig.PrePrompt
+	}
+	d.value("model_config.pre_prompt", oldPrompt, newPrompt)
+}
+
+func (d *differ) nodes(oldNodes, newNodes []Node) {
+	previous := make(map[string]Node, len(oldNodes))
+	for _, n := range oldNodes {
+		previous[n.ID] = n
+	}
+	current := make(map[string]bool, len(newNodes))
+	for _, n := range newNodes {
+		current[n.ID] = true
+		o, ok := previous[n.ID]
+		if !ok {
+			d.add(Added, "nodes."+n.ID, "", nodeSummary(n))
+			continue
+		}
+		path := "nodes." + n.ID
+		d.value(path+".type", o.Data.Type, n.Data.Type)
+		d.value(path+".parent", o.ParentID, n.ParentID)
+		d.model(path+".model", o.Data.Model, n.Data.Model)
+		modelRenamed := modelName(o.Data.Model) != modelName(n.Data.Model)
+
+		keys := slices.Collect(maps.Keys(o.Data.Raw))
+		for k := range n.Data.Raw {
+			if _, ok := o.Data.Raw[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			// The type, input variables and a renamed model are already reported above.
+			if uiKeys[k] || k == "type" || (k == "model" && modelRenamed) || (k == "variables" && n.Data.Type == NodeTypeStart) {
+				continue
+			}
+			d.raw(path+"."+k, o.Data.Raw[k], n.Data.Raw[k])
+		}
+	}
+	for _, n := range oldNodes {
+		if !current[n.ID] {
+			d.add(Removed, "nodes."+n.ID, nodeSummary(n), "")
+		}
+	}
+}
+
+// raw records the difference between two untyped configuration values.
+func (d *differ) raw(path string, oldValue, newValue any) {
+	switch {
+	case reflect.DeepEqual(oldValue, newValue):
+	case oldValue == nil:
+		d.add(Added, path, "", render(newValue))
+	case newValue == nil:
+		d.add(Removed, path, render(oldValue), "")
+	default:
+		d.add(Modified, path, render(oldValue), render(newValue))
+	}
+}
+
+// diffKeyed reports added, removed and modified elements of two lists matched by key.
+func diffKeyed[T any](d *differ, prefix string, oldItems, newItems []T, key, summary func(T) string) {
+	previous := make(map[string]T, len(oldItems))
+	for _, item := range oldItems {
+		previous[key(item)] = item
+	}
+	current := make(map[string]bool, len(newItems))
+	for _, item := range newItems {
+		k := key(item)
+		current[k] = true
+		o, ok := previous[k]
+		switch {
+		case !ok:
+			d.add(Added, prefix+"."+k, "", summary(item))
+		case summary(o) != summary(item):
+			d.add(Modified, prefix+"."+k, summary(o), summary(item))
+		}
+	}
+	for _, item := range oldItems {
+		if k := key(item); !current[k] {
+			d.add(Removed, prefix+"."+k, summary(item), "")
+		}
+	}
+}
+
+func workflowOf(doc *Document) Workflow {
+	if doc.Workflow == nil {
+		return Workflow{}
+	}
+	return *doc.Workflow
+}
+
+func modelName(m *Model) string {
+	if m == nil {
+		return ""
+	}
+	return m.Provider + "/" + m.Name
+}
+
+func inputSummary(v InputVariable) string {
+	s := v.Type
+	if v.Required {
+		s += " required"
+	}
+	if v.MaxLength > 0 {
+		s += fmt.Sprintf(" max_length=%d", v.MaxLength)
+	}
+	if len(v.Options) > 0 {
+		s += " options=" + strings.Join(v.Options, "|")
+	}
+	return s
+}
+
+func variableSummary(v Variable) string {
+	return v.ValueType + " " + render(v.Value)
+}
+
+func nodeSummary(n Node) string {
+	return fmt.Sprintf("%s %q", n.Data.Type, n.Data.Title)
+}
+
+func edgeKey(e Edge) string {
+	if e.SourceHandle == "" || e.SourceHandle == "source" {
+		return e.Source + "->" + e.Target
+	}
+	return e.Source + "[" + e.SourceHandle + "]->" + e.Target
+}
+
+// render formats a configuration value on a single line.
+func render(v any) string {
+	if s, ok := v.(string); ok {
+		return truncate(s)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return truncate(fmt.Sprint(v))
+	}
+	return truncate(string(data))
+}
+
+func truncate(s string) string {
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if utf8.RuneCountInString(s) <= maxValueLen {
+		return s
+	}
+	return string([]rune(s)[:maxValueLen]) + "..."
+}
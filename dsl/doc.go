@@ -0,0 +1,6 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dsl parses dify app DSL files, the YAML documents produced by exporting an app,
+// and provides structural linting and a semantic diff between two versions of an app.
+package dsl
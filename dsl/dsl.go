@@ -0,0 +1,244 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dsl
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// App modes.
+const (
+	ModeWorkflow     = "workflow"
+	ModeAdvancedChat = "advanced-chat"
+	ModeChat         = "chat"
+	ModeAgentChat    = "agent-chat"
+	ModeCompletion   = "completion"
+)
+
+// Node types with structural meaning in a workflow graph.
+const (
+	NodeTypeStart          = "start"
+	NodeTypeEnd            = "end"
+	NodeTypeAnswer         = "answer"
+	NodeTypeIteration      = "iteration"
+	NodeTypeIterationStart = "iteration-start"
+	NodeTypeLoop           = "loop"
+	NodeTypeLoopStart      = "loop-start"
+)
+
+// Document is a parsed app DSL file.
+type Document struct {
+	Kind         string       `yaml:"kind"`
+	Version      string       `yaml:"version"`
+	App          App          `yaml:"app"`
+	Workflow     *Workflow    `yaml:"workflow,omitempty"`
+	ModelConfig  *ModelConfig `yaml:"model_config,omitempty"`
+	Dependencies []Dependency `yaml:"dependencies,omitempty"`
+}
+
+// App holds the app metadata.
+type App struct {
+	Name           string `yaml:"name"`
+	Mode           string `yaml:"mode"`
+	Description    string `yaml:"description"`
+	Icon           string `yaml:"icon"`
+	IconBackground string `yaml:"icon_background"`
+}
+
+// Workflow holds the graph and variables of workflow and advanced-chat apps.
+type Workflow struct {
+	Graph                 Graph          `yaml:"graph"`
+	EnvironmentVariables  []Variable     `yaml:"environment_variables,omitempty"`
+	ConversationVariables []Variable     `yaml:"conversation_variables,omitempty"`
+	Features              map[string]any `yaml:"features,omitempty"`
+}
+
+// Variable is an environment or conversation variable.
+type Variable struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	ValueType   string `yaml:"value_type"`
+	Value       any    `yaml:"value"`
+	Description string `yaml:"description"`
+}
+
+// Graph is the workflow graph.
+type Graph struct {
+	Nodes []Node `yaml:"nodes"`
+	Edges []Edge `yaml:"edges"`
+}
+
+// Node is a workflow graph node. ParentID is set for nodes nested in an iteration or loop.
+type Node struct {
+	ID       string   `yaml:"id"`
+	ParentID string   `yaml:"parentId,omitempty"`
+	Data     NodeData `yaml:"data"`
+}
+
+// NodeData holds the node configuration. Only the fields needed for linting and diffing are typed;
+// Raw keeps the complete configuration.
+type NodeData struct {
+	Type      string           `yaml:"type"`
+	Title     string           `yaml:"title"`
+	Desc      string           `yaml:"desc"`
+	Variables []InputVariable  `yaml:"variables"`
+	Outputs   []OutputVariable `yaml:"outputs"`
+	Model     *Model           `yaml:"model"`
+	Raw       map[string]any   `yaml:"-"`
+}
+
+// UnmarshalYAML decodes the typed fields and keeps the complete configuration in Raw.
+// Variables and outputs have other shapes outside the start and end nodes, so they are only
+// decoded for those node types.
+func (d *NodeData) UnmarshalYAML(value *yaml.Node) error {
+	var head struct {
+		Type  string `yaml:"type"`
+		Title string `yaml:"title"`
+		Desc  string `yaml:"desc"`
+		Model *Model `yaml:"model"`
+	}
+	if err := value.Decode(&head); err != nil {
+		return err
+	}
+	*d = NodeData{Type: head.Type, Title: head.Title, Desc: head.Desc, Model: head.Model}
+	if err := value.Decode(&d.Raw); err != nil {
+		return err
+	}
+
+	switch d.Type {
+	case NodeTypeStart:
+		var start struct {
+			Variables []InputVariable `yaml:"variables"`
+		}
+		if err := value.Decode(&start); err != nil {
+			return err
+		}
+		d.Variables = start.Variables
+	case NodeTypeEnd:
+		var end struct {
+			Outputs []OutputVariable `yaml:"outputs"`
+		}
+		if err := value.Decode(&end); err != nil {
+			return err
+		}
+		d.Outputs = end.Outputs
+	}
+	return nil
+}
+
+// Edge connects two nodes of the workflow graph.
+type Edge struct {
+	ID           string `yaml:"id"`
+	Source       string `yaml:"source"`
+	Target       string `yaml:"target"`
+	SourceHandle string `yaml:"sourceHandle"`
+	TargetHandle string `yaml:"targetHandle"`
+}
+
+// InputVariable is a variable of the start node, i.e. a control in the app's input form.
+type InputVariable struct {
+	Variable                 string   `yaml:"variable"`
+	Label                    string   `yaml:"label"`
+	Type                     string   `yaml:"type"`
+	Required                 bool     `yaml:"required"`
+	MaxLength                int      `yaml:"max_length"`
+	Options                  []string `yaml:"options"`
+	Default                  any      `yaml:"default"`
+	AllowedFileTypes         []string `yaml:"allowed_file_types"`
+	AllowedFileExtensions    []string `yaml:"allowed_file_extensions"`
+	AllowedFileUploadMethods []string `yaml:"allowed_file_upload_methods"`
+}
+
+// OutputVariable is an output of the end node.
+type OutputVariable struct {
+	Variable      string   `yaml:"variable"`
+	ValueType     string   `yaml:"value_type"`
+	ValueSelector []string `yaml:"value_selector"`
+}
+
+// Model is a model configuration used by LLM-based nodes and chat apps.
+type Model struct {
+	Provider         string         `yaml:"provider"`
+	Name             string         `yaml:"name"`
+	Mode             string         `yaml:"mode"`
+	CompletionParams map[string]any `yaml:"completion_params,omitempty"`
+}
+
+// ModelConfig holds the configuration of chat, agent-chat and completion apps.
+type ModelConfig struct {
+	Model     *Model         `yaml:"model"`
+	PrePrompt string         `yaml:"pre_prompt"`
+	Raw       map[string]any `yaml:",inline"`
+}
+
+// Dependency is a plugin the app depends on.
+type Dependency struct {
+	CurrentIdentifier string         `yaml:"current_identifier"`
+	Type              string         `yaml:"type"`
+	Value             map[string]any `yaml:"value"`
+}
+
+// Parse parses an app DSL document.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse app DSL: %w", err)
+	}
+	return &doc, nil
+}
+
+// ParseFile reads and parses an app DSL file.
+func ParseFile(name string) (*Document, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// IsWorkflow reports whether the app is driven by a workflow graph.
+func (d *Document) IsWorkflow() bool {
+	return d.App.Mode == ModeWorkflow || d.App.Mode == ModeAdvancedChat
+}
+
+// Nodes returns the workflow graph nodes, or nil for apps without a workflow.
+func (d *Document) Nodes() []Node {
+	if d.Workflow == nil {
+		return nil
+	}
+	return d.Workflow.Graph.Nodes
+}
+
+// Node returns the node with the given ID.
+func (d *Document) Node(id string) (Node, bool) {
+	for _, n := range d.Nodes() {
+		if n.ID == id {
+			return n, true
+		}
+	}
+	return Node{}, false
+}
+
+// StartNode returns the start node of the workflow.
+func (d *Document) StartNode() (Node, bool) {
+	for _, n := range d.Nodes() {
+		if n.Data.Type == NodeTypeStart {
+			return n, true
+		}
+	}
+	return Node{}, false
+}
+
+// EndNode returns the end node of the workflow. Advanced-chat apps answer instead and have none.
+func (d *Document) EndNode() (Node, bool) {
+	for _, n := range d.Nodes() {
+		if n.Data.Type == NodeTypeEnd {
+			return n, true
+		}
+	}
+	return Node{}, false
+}
@@ -0,0 +1,123 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dsl
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const appDSL = `
+app:
+  name: summarizer
+  mode: workflow
+kind: app
+version: 0.1.5
+workflow:
+  environment_variables:
+  - name: TONE
+    value_type: string
+    value: formal
+  graph:
+    edges:
+    - {id: e1, source: start, target: llm}
+    - {id: e2, source: llm, target: end}
+    nodes:
+    - id: start
+      data:
+        type: start
+        title: Start
+        variables:
+        - {variable: text, label: Text, type: paragraph, required: true}
+    - id: llm
+      data:
+        type: llm
+        title: LLM
+        model: {provider: openai, name: gpt-4o, mode: chat}
+        prompt_template:
+        - role: system
+          text: "Summarize {{#start.text#}} in a {{#env.TONE#}} tone"
+    - id: end
+      data:
+        type: end
+        title: End
+        outputs:
+        - {variable: summary, value_type: string, value_selector: [llm, text]}
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(appDSL))
+	require.NoError(t, err)
+
+	assert.True(t, doc.IsWorkflow())
+	start, ok := doc.StartNode()
+	require.True(t, ok)
+	assert.Equal(t, "paragraph", start.Data.Variables[0].Type)
+	end, ok := doc.EndNode()
+	require.True(t, ok)
+	assert.Equal(t, []string{"llm", "text"}, end.Data.Outputs[0].ValueSelector)
+	llm, ok := doc.Node("llm")
+	require.True(t, ok)
+	assert.Equal(t, "gpt-4o", llm.Data.Model.Name)
+	assert.Empty(t, Lint(doc))
+}
+
+func TestLint(t *testing.T) {
+	doc, err := Parse([]byte(appDSL))
+	require.NoError(t, err)
+
+	graph := &doc.Workflow.Graph
+	graph.Edges = append(graph.Edges, Edge{ID: "e3", Source: "llm", Target: "missing"})
+	graph.Nodes = append(graph.Nodes, Node{ID: "code", Data: NodeData{
+		Type:  "code",
+		Title: "Code",
+		Raw: map[string]any{"variables": []any{
+			map[string]any{"variable": "x", "value_selector": []any{"start", "query"}},
+		}},
+	}})
+
+	var messages []string
+	for _, issue := range Lint(doc) {
+		messages = append(messages, issue.String())
+	}
+	assert.Equal(t, []string{
+		`error: edge e3 has unknown target "missing"`,
+		`warning: node code: code node "Code" is unreachable`,
+		`error: node code: reference to undefined input variable start.query`,
+	}, messages)
+}
+
+func TestDiff(t *testing.T) {
+	old, err := Parse([]byte(appDSL))
+	require.NoError(t, err)
+	updated, err := Parse([]byte(appDSL))
+	require.NoError(t, err)
+	assert.Empty(t, Diff(old, updated))
+
+	updated.Workflow.Graph.Nodes[1].Data.Model = &Model{Provider: "openai", Name: "gpt-4o-mini"}
+	updated.Workflow.Graph.Nodes[1].Data.Raw["title"] = "Summarize"
+	updated.Workflow.Graph.Nodes[0].Data.Variables[0].Required = false
+
+	var lines []string
+	for _, c := range Diff(old, updated) {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		"~ inputs.text: paragraph required -> paragraph",
+		"~ nodes.llm.model: openai/gpt-4o -> openai/gpt-4o-mini",
+		"~ nodes.llm.title: LLM -> Summarize",
+	}, lines)
+}
+
+func TestTruncate(t *testing.T) {
+	s := strings.Repeat("提示", maxValueLen)
+	got := truncate(s)
+	assert.True(t, utf8.ValidString(got))
+	assert.Equal(t, strings.Repeat("提示", maxValueLen/2)+"...", got)
+	assert.Equal(t, `a\nb`, truncate("a\nb"))
+}
@@ -0,0 +1,287 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dsl
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Severity classifies a lint issue.
+type Severity string
+
+const (
+	// SeverityError marks problems that prevent the app from running correctly.
+	SeverityError Severity = "error"
+	// SeverityWarning marks suspicious constructs that may be intended.
+	SeverityWarning Severity = "warning"
+)
+
+// Variable selector scopes that do not refer to a node.
+const (
+	scopeSystem       = "sys"
+	scopeEnvironment  = "env"
+	scopeConversation = "conversation"
+)
+
+// templateReference matches variable references such as {{#node_id.variable#}} in prompts and answers.
+var templateReference = regexp.MustCompile(`\{\{#([a-zA-Z0-9_]+)\.([a-zA-Z0-9_.]+)#\}\}`)
+
+// Issue is a structural problem found by Lint.
+type Issue struct {
+	Severity Severity
+	NodeID   string
+	Message  string
+}
+
+// String returns the issue in a compiler-like format.
+func (i Issue) String() string {
+	if i.NodeID == "" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: node %s: %s", i.Severity, i.NodeID, i.Message)
+}
+
+// Lint reports structural problems of the workflow graph: missing start or end nodes, duplicate
+// node IDs, dangling edges, unreachable nodes and references to undefined variables.
+func Lint(doc *Document) []Issue {
+	if !doc.IsWorkflow() {
+		return nil
+	}
+	if doc.Workflow == nil {
+		return []Issue{{Severity: SeverityError, Message: fmt.Sprintf("%s app has no workflow", doc.App.Mode)}}
+	}
+
+	l := &linter{doc: doc, nodes: make(map[string]Node)}
+	l.checkNodes()
+	l.checkEdges()
+	l.checkReachability()
+	l.checkReferences()
+	return l.issues
+}
+
+type linter struct {
+	doc    *Document
+	nodes  map[string]Node
+	issues []Issue
+}
+
+func (l *linter) report(severity Severity, nodeID, format string, args ...any) {
+	l.issues = append(l.issues, Issue{Severity: severity, NodeID: nodeID, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) checkNodes() {
+	counts := make(map[string]int)
+	for _, n := range l.doc.Nodes() {
+		if _, ok := l.nodes[n.ID]; ok {
+			l.report(SeverityError, n.ID, "duplicate node id")
+		}
+		l.nodes[n.ID] = n
+		counts[n.Data.Type]++
+
+		if n.Data.Type == NodeTypeStart {
+			seen := make(map[string]bool)
+			for _, v := range n.Data.Variables {
+				if seen[v.Variable] {
+					l.report(SeverityError, n.ID, "duplicate input variable %q", v.Variable)
+				}
+				seen[v.Variable] = true
+			}
+		}
+	}
+
+	switch {
+	case counts[NodeTypeStart] == 0:
+		l.report(SeverityError, "", "missing start node")
+	case counts[NodeTypeStart] > 1:
+		l.report(SeverityError, "", "multiple start nodes")
+	}
+	if l.doc.App.Mode == ModeWorkflow && counts[NodeTypeEnd] == 0 {
+		l.report(SeverityError, "", "missing end node")
+	}
+	if l.doc.App.Mode == ModeAdvancedChat && counts[NodeTypeAnswer] == 0 {
+		l.report(SeverityError, "", "missing answer node")
+	}
+}
+
+func (l *linter) checkEdges() {
+	for _, e := range l.doc.Workflow.Graph.Edges {
+		if _, ok := l.nodes[e.Source]; !ok {
+			l.report(SeverityError, "", "edge %s has unknown source %q", e.ID, e.Source)
+		}
+		if _, ok := l.nodes[e.Target]; !ok {
+			l.report(SeverityError, "", "edge %s has unknown target %q", e.ID, e.Target)
+		}
+	}
+}
+
+// checkReachability reports nodes that cannot be reached from the start node. Nodes nested in an
+// iteration or loop are reached from their own start node, which is entered through the parent.
+func (l *linter) checkReachability() {
+	start, ok := l.doc.StartNode()
+	if !ok {
+		return
+	}
+	reachable := l.reachableFrom(start.ID)
+	for _, n := range l.doc.Nodes() {
+		// Notes on the canvas have no type and are not part of the graph.
+		if !reachable[n.ID] && n.Data.Type != "" {
+			l.report(SeverityWarning, n.ID, "%s node %q is unreachable", n.Data.Type, n.Data.Title)
+		}
+	}
+}
+
+// reachableFrom returns the set of nodes reachable from the given node, entering nested graphs.
+func (l *linter) reachableFrom(id string) map[string]bool {
+	next := make(map[string][]string)
+	for _, e := range l.doc.Workflow.Graph.Edges {
+		next[e.Source] = append(next[e.Source], e.Target)
+	}
+	for _, n := range l.doc.Nodes() {
+		if n.ParentID != "" && (n.Data.Type == NodeTypeIterationStart || n.Data.Type == NodeTypeLoopStart) {
+			next[n.ParentID] = append(next[n.ParentID], n.ID)
+		}
+	}
+
+	reachable := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, target := range next[current] {
+			if !reachable[target] {
+				reachable[target] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+	return reachable
+}
+
+// checkReferences reports variable selectors and template references that point to unknown nodes,
+// undefined start, environment or conversation variables, or nodes that do not run before the referrer.
+func (l *linter) checkReferences() {
+	for _, n := range l.doc.Nodes() {
+		upstream := l.upstreamOf(n)
+		for _, ref := range References(n.Data.Raw) {
+			l.checkReference(n, ref, upstream)
+		}
+	}
+}
+
+func (l *linter) checkReference(n Node, ref []string, upstream map[string]bool) {
+	const minSelectorLen = 2
+	if len(ref) < minSelectorLen {
+		return
+	}
+	scope, name := ref[0], ref[1]
+	selector := strings.Join(ref, ".")
+
+	switch scope {
+	case scopeSystem:
+		return
+	case scopeEnvironment:
+		if !slices.ContainsFunc(l.doc.Workflow.EnvironmentVariables, func(v Variable) bool { return v.Name == name }) {
+			l.report(SeverityError, n.ID, "reference to undefined environment variable %s", selector)
+		}
+		return
+	case scopeConversation:
+		if !slices.ContainsFunc(l.doc.Workflow.ConversationVariables, func(v Variable) bool { return v.Name == name }) {
+			l.report(SeverityError, n.ID, "reference to undefined conversation variable %s", selector)
+		}
+		return
+	}
+
+	target, ok := l.nodes[scope]
+	if !ok {
+		l.report(SeverityError, n.ID, "reference to unknown node %s", selector)
+		return
+	}
+	if target.Data.Type == NodeTypeStart &&
+		!slices.ContainsFunc(target.Data.Variables, func(v InputVariable) bool { return v.Variable == name }) {
+		l.report(SeverityError, n.ID, "reference to undefined input variable %s", selector)
+		return
+	}
+	// Iterations and loops select the outputs of the nodes nested in them.
+	if !upstream[scope] && target.ParentID != n.ID {
+		l.report(SeverityWarning, n.ID, "reference to %s, which does not run before this node", selector)
+	}
+}
+
+// upstreamOf returns the nodes that run before n, including the iterations or loops enclosing it
+// and everything that runs before them.
+func (l *linter) upstreamOf(n Node) map[string]bool {
+	prev := make(map[string][]string)
+	for _, e := range l.doc.Workflow.Graph.Edges {
+		prev[e.Target] = append(prev[e.Target], e.Source)
+	}
+
+	upstream := make(map[string]bool)
+	queue := []string{n.ID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		sources := slices.Clone(prev[current])
+		if parent := l.nodes[current].ParentID; parent != "" {
+			sources = append(sources, parent)
+		}
+		for _, source := range sources {
+			if !upstream[source] {
+				upstream[source] = true
+				queue = append(queue, source)
+			}
+		}
+	}
+	return upstream
+}
+
+// References returns the variable selectors used in a node configuration: value selectors such as
+// [node_id, variable] and template references such as {{#node_id.variable#}}.
+func References(data any) [][]string {
+	var refs [][]string
+	var walk func(key string, v any)
+	walk = func(key string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				walk(k, v[k])
+			}
+		case []any:
+			if strings.HasSuffix(key, "selector") {
+				if sel, ok := selector(v); ok {
+					refs = append(refs, sel)
+					return
+				}
+			}
+			for _, child := range v {
+				walk(key, child)
+			}
+		case string:
+			for _, m := range templateReference.FindAllStringSubmatch(v, -1) {
+				refs = append(refs, append([]string{m[1]}, strings.Split(m[2], ".")...))
+			}
+		}
+	}
+	walk("", data)
+	return refs
+}
+
+// selector converts a YAML list into a variable selector when every element is a string.
+func selector(v []any) ([]string, bool) {
+	if len(v) == 0 {
+		return nil, false
+	}
+	sel := make([]string, 0, len(v))
+	for _, item := range v {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		sel = append(sel, s)
+	}
+	return sel, true
+}
@@ -0,0 +1,6 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package codegen generates typed Go clients bound to a single dify app
+// from the app's input form and, when known, its workflow outputs.
+package codegen
@@ -0,0 +1,41 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package codegen
+
+import (
+	"errors"
+
+	"github.com/yeeaiclub/dify-go/dsl"
+)
+
+// SpecFromDSL builds a spec from an exported app DSL file. Inputs come from the start node;
+// outputs come from the end node and are left nil for apps without one.
+func SpecFromDSL(pkg string, data []byte) (Spec, error) {
+	doc, err := dsl.Parse(data)
+	if err != nil {
+		return Spec{}, err
+	}
+	start, ok := doc.StartNode()
+	if !ok {
+		return Spec{}, errors.New("app DSL has no start node")
+	}
+
+	spec := Spec{Package: pkg, App: doc.App.Name, Inputs: make([]Field, 0, len(start.Data.Variables))}
+	for _, v := range start.Data.Variables {
+		spec.Inputs = append(spec.Inputs, Field{
+			Variable: v.Variable,
+			Type:     v.Type,
+			Required: v.Required,
+			Options:  v.Options,
+			Label:    v.Label,
+		})
+	}
+	if end, ok := doc.EndNode(); ok {
+		spec.Outputs = make([]Field, 0, len(end.Data.Outputs))
+		for _, o := range end.Data.Outputs {
+			spec.Outputs = append(spec.Outputs, Field{Variable: o.Variable, Type: o.ValueType})
+		}
+	}
+	return spec, nil
+}
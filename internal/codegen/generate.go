@@ -0,0 +1,252 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package codegen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// signaturePrefix marks the line of a generated file that records the spec it was generated from.
+const signaturePrefix = "// dify-gen:signature "
+
+// commonInitialisms are the name parts rendered in upper case, following Go naming conventions.
+var commonInitialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "json": true, "llm": true,
+	"pdf": true, "sql": true, "uri": true, "url": true, "uuid": true,
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by dify-gen. DO NOT EDIT.
+{{ .Signature }}
+
+// Package {{ .Package }} provides a typed client for the {{ .App }} dify app.
+package {{ .Package }}
+
+import (
+	"context"
+	"iter"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// Inputs is the input form of the app.
+type Inputs struct {
+{{- range .Inputs }}
+	// {{ .Name }} is the {{ .Type }} input {{ .Quoted }}{{ if .Label }} ({{ .Label }}){{ end }}.
+{{- if .Options }}
+	// Accepted values: {{ .Options }}.
+{{- end }}
+	{{ .Name }} {{ .GoType }} ` + "`" + `json:"{{ .Variable }}{{ if not .Required }},omitempty{{ end }}"` + "`" + `
+{{- end }}
+}
+{{ if .Outputs }}
+// Outputs holds the outputs of the app's end node.
+type Outputs struct {
+{{- range .Outputs }}
+	// {{ .Name }} is the output {{ .Quoted }}.
+	{{ .Name }} {{ .GoType }} ` + "`" + `json:"{{ .Variable }}"` + "`" + `
+{{- end }}
+}
+{{ else }}
+// Outputs holds the outputs of the app. The app does not declare them, so they are left untyped.
+type Outputs map[string]any
+{{ end }}
+// Client runs the app.
+type Client struct {
+	workflow *v1.WorkflowService
+}
+
+// New creates a client for the app using the app's API key.
+func New(baseURL, apiKey string, opts ...v1.WorkflowOption) *Client {
+	return &Client{workflow: v1.NewWorkflowService(baseURL, apiKey, opts...)}
+}
+
+// Run executes the app in blocking mode and decodes its outputs.
+func (c *Client) Run(ctx context.Context, user string, in Inputs) (Outputs, schema.RunWorkflowResponse, error) {
+	return v1.RunTyped[Inputs, Outputs](ctx, c.workflow, v1.TypedRunRequest[Inputs]{Inputs: in, User: user})
+}
+
+// RunStream executes the app in streaming mode. The workflow_finished event carries the decoded outputs.
+func (c *Client) RunStream(ctx context.Context, user string, in Inputs) (iter.Seq2[v1.TypedStreamEvent[Outputs], error], error) {
+	return v1.RunStreamTyped[Inputs, Outputs](ctx, c.workflow, v1.TypedRunRequest[Inputs]{Inputs: in, User: user})
+}
+`))
+
+// templateField is a field as rendered by the file template.
+type templateField struct {
+	Field
+	Name   string
+	GoType string
+	Quoted string
+	// Label and Options shadow the fields of Field with single line text for comments.
+	Label   string
+	Options string
+}
+
+// Generate renders the Go source of a client for spec. The output only depends on spec,
+// so regenerating an unchanged app produces identical bytes.
+func Generate(spec Spec) ([]byte, error) {
+	if spec.Package == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	signature, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	app := spec.App
+	if app == "" {
+		app = spec.Package
+	}
+
+	data := map[string]any{
+		"Signature": signaturePrefix + string(signature),
+		"Package":   spec.Package,
+		"App":       singleLine(app),
+		"Inputs":    templateFields(spec.Inputs, inputGoType),
+		"Outputs":   templateFields(spec.Outputs, outputGoType),
+	}
+	var buf bytes.Buffer
+	if err = fileTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render client: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated client: %w", err)
+	}
+	return src, nil
+}
+
+// ReadSignature extracts the spec recorded in a previously generated file.
+// It reports false when src was not generated by this package.
+func ReadSignature(src []byte) (Spec, bool, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	scanner.Buffer(nil, len(src)+1)
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), signaturePrefix)
+		if !ok {
+			continue
+		}
+		var spec Spec
+		if err := json.Unmarshal([]byte(line), &spec); err != nil {
+			return Spec{}, false, fmt.Errorf("failed to decode generated signature: %w", err)
+		}
+		return spec, true, nil
+	}
+	return Spec{}, false, scanner.Err()
+}
+
+func templateFields(fields []Field, goType func(Field) string) []templateField {
+	if fields == nil {
+		return nil
+	}
+	used := make(map[string]int, len(fields))
+	out := make([]templateField, 0, len(fields))
+	for _, f := range fields {
+		name := goName(f.Variable)
+		if n := used[name]; n > 0 {
+			used[name]++
+			name += strconv.Itoa(n + 1)
+		} else {
+			used[name] = 1
+		}
+		options := make([]string, len(f.Options))
+		for i, option := range f.Options {
+			options[i] = singleLine(option)
+		}
+		out = append(out, templateField{
+			Field:   f,
+			Name:    name,
+			GoType:  goType(f),
+			Quoted:  strconv.Quote(f.Variable),
+			Label:   singleLine(f.Label),
+			Options: strings.Join(options, ", "),
+		})
+	}
+	return out
+}
+
+// singleLine collapses the whitespace of s, including line breaks, so that it fits in a line comment.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// inputGoType maps an input control type to a Go type. Optional scalar inputs use pointers
+// so that zero values can still be sent explicitly.
+func inputGoType(f Field) string {
+	var typ string
+	switch f.Type {
+	case schema.InputTypeTextInput, schema.InputTypeParagraph, schema.InputTypeSelect:
+		return "string"
+	case schema.InputTypeFileList:
+		return "[]schema.RunWorkflowRequestFile"
+	case schema.InputTypeNumber:
+		typ = "float64"
+	case schema.InputTypeCheckbox:
+		typ = "bool"
+	case schema.InputTypeFile:
+		typ = "schema.RunWorkflowRequestFile"
+	default:
+		return "any"
+	}
+	if f.Required {
+		return typ
+	}
+	return "*" + typ
+}
+
+// outputGoType maps an output value type to a Go type.
+func outputGoType(f Field) string {
+	switch f.Type {
+	case "string":
+		return "string"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "object", "file":
+		return "map[string]any"
+	case "array[string]":
+		return "[]string"
+	case "array[number]":
+		return "[]float64"
+	case "array[boolean]":
+		return "[]bool"
+	case "array[object]", "array[file]":
+		return "[]map[string]any"
+	default:
+		return "any"
+	}
+}
+
+// goName converts a dify variable name into an exported Go identifier.
+func goName(variable string) string {
+	parts := strings.FieldsFunc(variable, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, p := range parts {
+		if commonInitialisms[strings.ToLower(p)] {
+			b.WriteString(strings.ToUpper(p))
+			continue
+		}
+		runes := []rune(p)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "V" + name
+	}
+	return name
+}
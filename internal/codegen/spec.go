@@ -0,0 +1,124 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// Field describes an app variable that becomes a field of a generated struct.
+type Field struct {
+	// Variable is the variable name used by dify.
+	Variable string `json:"variable"`
+	// Type is the dify input control type or output value type.
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Label    string   `json:"-"`
+}
+
+// Spec describes the app a client is generated for.
+type Spec struct {
+	// Package is the name of the generated package.
+	Package string `json:"-"`
+	// App is the app name, used in doc comments only.
+	App    string  `json:"-"`
+	Inputs []Field `json:"inputs"`
+	// Outputs is nil when the app's outputs are unknown, which generates a map type.
+	Outputs []Field `json:"outputs,omitempty"`
+}
+
+// SpecFromParameters builds a spec from the app parameters returned by the parameters endpoint.
+// The parameters do not describe outputs, so Outputs is left nil.
+func SpecFromParameters(pkg string, params schema.ApplicationParameters) (Spec, error) {
+	vars, err := params.InputVariables()
+	if err != nil {
+		return Spec{}, err
+	}
+	return Spec{Package: pkg, Inputs: inputFields(vars)}, nil
+}
+
+// SpecFromParametersJSON builds a spec from a saved parameters endpoint response.
+func SpecFromParametersJSON(pkg string, data []byte) (Spec, error) {
+	var params schema.ApplicationParameters
+	if err := json.Unmarshal(data, &params); err != nil {
+		return Spec{}, fmt.Errorf("failed to decode app parameters: %w", err)
+	}
+	return SpecFromParameters(pkg, params)
+}
+
+// inputFields converts input variables into fields.
+func inputFields(vars []schema.InputVariable) []Field {
+	fields := make([]Field, 0, len(vars))
+	for _, v := range vars {
+		fields = append(fields, Field{
+			Variable: v.Variable,
+			Type:     v.Type,
+			Required: v.Required,
+			Options:  v.Options,
+			Label:    v.Label,
+		})
+	}
+	return fields
+}
+
+// Breaking lists the changes from old to spec that break code written against old:
+// removed or retyped variables, inputs that became required and removed select options.
+func Breaking(old, spec Spec) []string {
+	var changes []string
+
+	inputs := fieldIndex(spec.Inputs)
+	for _, o := range old.Inputs {
+		n, ok := inputs[o.Variable]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("input %q was removed", o.Variable))
+			continue
+		}
+		if n.Type != o.Type {
+			changes = append(changes, fmt.Sprintf("input %q changed type from %s to %s", o.Variable, o.Type, n.Type))
+			continue
+		}
+		if n.Required && !o.Required {
+			changes = append(changes, fmt.Sprintf("input %q became required", o.Variable))
+		}
+		for _, opt := range o.Options {
+			if !slices.Contains(n.Options, opt) {
+				changes = append(changes, fmt.Sprintf("input %q no longer accepts option %q", o.Variable, opt))
+			}
+		}
+	}
+	previous := fieldIndex(old.Inputs)
+	for _, n := range spec.Inputs {
+		if _, ok := previous[n.Variable]; !ok && n.Required {
+			changes = append(changes, fmt.Sprintf("required input %q was added", n.Variable))
+		}
+	}
+
+	if old.Outputs != nil && spec.Outputs == nil {
+		return append(changes, "outputs are no longer known")
+	}
+	outputs := fieldIndex(spec.Outputs)
+	for _, o := range old.Outputs {
+		n, ok := outputs[o.Variable]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("output %q was removed", o.Variable))
+		case n.Type != o.Type:
+			changes = append(changes, fmt.Sprintf("output %q changed type from %s to %s", o.Variable, o.Type, n.Type))
+		}
+	}
+	return changes
+}
+
+func fieldIndex(fields []Field) map[string]Field {
+	index := make(map[string]Field, len(fields))
+	for _, f := range fields {
+		index[f.Variable] = f
+	}
+	return index
+}
@@ -0,0 +1,85 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	spec := Spec{
+		Package: "summarizer",
+		Inputs: []Field{
+			{Variable: "query", Type: "paragraph", Required: true},
+			{Variable: "top_k", Type: "number"},
+		},
+		Outputs: []Field{{Variable: "text", Type: "string"}},
+	}
+
+	src, err := Generate(spec)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "TopK *float64 `json:\"top_k,omitempty\"`")
+	assert.Contains(t, string(src), "Text string `json:\"text\"`")
+
+	again, err := Generate(spec)
+	require.NoError(t, err)
+	assert.Equal(t, src, again)
+
+	old, ok, err := ReadSignature(src)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, spec.Inputs, old.Inputs)
+	assert.Empty(t, Breaking(old, spec))
+}
+
+func TestGenerateMultilineText(t *testing.T) {
+	spec := Spec{
+		Package: "classifier",
+		App:     "Ticket\nclassifier",
+		Inputs: []Field{{
+			Variable: "category",
+			Type:     "select",
+			Required: true,
+			Label:    "Category\n}\nfunc init() { panic(1) }",
+			Options:  []string{"bug\nreport", "question"},
+		}},
+	}
+
+	src, err := Generate(spec)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), `// Category is the select input "category" (Category } func init() { panic(1) }).`)
+	assert.Contains(t, string(src), "// Accepted values: bug report, question.")
+	assert.Contains(t, string(src), "typed client for the Ticket classifier dify app")
+}
+
+func TestBreaking(t *testing.T) {
+	old := Spec{
+		Inputs: []Field{
+			{Variable: "query", Type: "paragraph", Required: true},
+			{Variable: "lang", Type: "select", Options: []string{"en", "zh"}},
+			{Variable: "top_k", Type: "number"},
+		},
+		Outputs: []Field{{Variable: "text", Type: "string"}},
+	}
+	spec := Spec{
+		Inputs: []Field{
+			{Variable: "query", Type: "paragraph", Required: true},
+			{Variable: "lang", Type: "select", Required: true, Options: []string{"en"}},
+			{Variable: "user_id", Type: "text-input", Required: true},
+			{Variable: "tone", Type: "text-input"},
+		},
+		Outputs: []Field{{Variable: "text", Type: "object"}},
+	}
+
+	assert.Equal(t, []string{
+		`input "lang" became required`,
+		`input "lang" no longer accepts option "zh"`,
+		`input "top_k" was removed`,
+		`required input "user_id" was added`,
+		`output "text" changed type from string to object`,
+	}, Breaking(old, spec))
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"maps"
 	"net/http"
 	"net/url"
 	"time"
@@ -81,6 +82,9 @@ func WithIdleConnTimeout(timeout time.Duration) ClientOption {
 // Client is a http client that execute requests.
 type Client struct {
 	client *http.Client
+	// raw shares the transport of client without its whole-request timeout, for SendRaw and SendStream.
+	raw     *http.Client
+	timeout time.Duration
 }
 
 // NewClient returns a client to execute requests.
@@ -107,7 +111,7 @@ func NewClient(opts ...ClientOption) *Client {
 		Timeout:   opt.Timeout,
 		Transport: transport,
 	}
-	return &Client{client: client}
+	return &Client{client: client, raw: &http.Client{Transport: transport}, timeout: opt.Timeout}
 }
 
 // Send sends a HTTP request and returns response.
@@ -119,29 +123,83 @@ func (c *Client) Send(ctx context.Context, req Request) (*Response, error) {
 	return c.doRequest(httpReq)
 }
 
-// SendStream sends an HTTP request and returns streaming responses via the provided channel.
+// SendStream sends an HTTP request and returns the server-sent events of the response. Like SendRaw,
+// the client timeout only bounds the wait for the response headers and reading the events is
+// bounded by ctx, so that streams may outlive the timeout.
 func (c *Client) SendStream(ctx context.Context, req Request) (iter.Seq2[[]byte, error], error) {
+	headers := maps.Clone(req.Headers)
+	if headers == nil {
+		headers = make(map[string]string, 3)
+	}
+	headers["Accept"] = "text/event-stream"
+	headers["Cache-Control"] = "no-cache"
+	headers["Connection"] = "keep-alive"
+	req.Headers = headers
+
+	resp, err := c.SendRaw(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close() //nolint:gosec // ignoring error as response body is being discarded on error path
+		return nil, fmt.Errorf("HTTP error resp code: %d", resp.StatusCode)
+	}
+	return sseHandler(resp.Body), nil
+}
+
+// SendRaw sends an HTTP request and returns the unread response, whatever its status code.
+// The client timeout only bounds the wait for the response headers, so that long bodies can be
+// streamed; reading the body is bounded by ctx. The caller must close the response body.
+func (c *Client) SendRaw(ctx context.Context, req Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
 	httpReq, err := c.buildRequest(ctx, req)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
-	httpReq.Header.Set("Accept", "text/event-stream")
-	httpReq.Header.Set("Cache-Control", "no-cache")
-	httpReq.Header.Set("Connection", "keep-alive")
-	return c.doStreamRequest(ctx, httpReq)
+	var timer *time.Timer
+	if c.timeout > 0 {
+		timer = time.AfterFunc(c.timeout, cancel)
+	}
+	resp, err := c.raw.Do(httpReq)
+	if timer != nil {
+		timer.Stop()
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
 }
 
-// marshalBody serializes the request body to JSON.
-func (c *Client) marshalBody(body any) (io.Reader, error) {
+// cancelBody releases the context of a raw request when its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// marshalBody serializes the request body to JSON, or to multipart/form-data for a *MultipartBody,
+// and returns it with its content type.
+func (c *Client) marshalBody(body any) (io.Reader, string, error) {
 	if body == nil {
-		return nil, nil
+		return nil, "application/json", nil
+	}
+	if form, ok := body.(*MultipartBody); ok {
+		return form.encode()
 	}
 
 	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, "", fmt.Errorf("failed to marshal request body: %w", err)
 	}
-	return bytes.NewBuffer(jsonData), nil
+	return bytes.NewBuffer(jsonData), "application/json", nil
 }
 
 // buildRequest builds a new HTTP request with the given parameters.
@@ -152,7 +210,7 @@ func (c *Client) buildRequest(ctx context.Context, req Request) (*http.Request,
 		return nil, err
 	}
 
-	reqBody, err := c.marshalBody(req.Body)
+	reqBody, contentType, err := c.marshalBody(req.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +223,7 @@ func (c *Client) buildRequest(ctx context.Context, req Request) (*http.Request,
 	for key, value := range req.Headers {
 		httpReq.Header.Set(key, value)
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Content-Type", contentType)
 	httpReq.Header.Set("Authorization", "Bearer "+req.AuthToken)
 
 	return httpReq, nil
@@ -196,21 +254,6 @@ func (c *Client) doRequest(req *http.Request) (*Response, error) {
 	}, nil
 }
 
-// doStreamRequest executes the HTTP request and returns streaming responses via the provided channel.
-func (c *Client) doStreamRequest(ctx context.Context, req *http.Request) (iter.Seq2[[]byte, error], error) {
-	ctxReq := req.WithContext(ctx)
-	resp, err := c.client.Do(ctxReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close() //nolint:gosec // ignoring error as response body is being discarded on error path
-		return nil, fmt.Errorf("HTTP error resp code: %d", resp.StatusCode)
-	}
-	return sseHandler(resp.Body), nil
-}
-
 // buildURL constructs a complete URL from base URL, path, and query parameters.
 func buildURL(baseURL string, path string, queryStructs []any) (string, error) {
 	fullURL, err := url.JoinPath(baseURL, path)
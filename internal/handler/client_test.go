@@ -0,0 +1,61 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendRawStreamsPastTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "first ")
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "second")
+	}))
+	defer server.Close()
+
+	client := NewClient(WithTimeout(30 * time.Millisecond))
+	req, err := NewRequestBuilder().BaseURL(server.URL).Path("audio").Method(http.MethodPost).Build()
+	require.NoError(t, err)
+	resp, err := client.SendRaw(t.Context(), req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "first second", string(body))
+}
+
+func TestSendStreamPastTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "data: second\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(WithTimeout(30 * time.Millisecond))
+	req, err := NewRequestBuilder().BaseURL(server.URL).Path("stream").Method(http.MethodPost).Build()
+	require.NoError(t, err)
+	events, err := client.SendStream(t.Context(), req)
+	require.NoError(t, err)
+
+	var got []string
+	for data, err := range events {
+		require.NoError(t, err)
+		got = append(got, string(data))
+	}
+	assert.Equal(t, []string{"first", "second"}, got)
+}
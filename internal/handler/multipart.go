@@ -0,0 +1,62 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// quoteEscaper escapes the quoted parameters of a Content-Disposition header, as mime/multipart does.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// FormFile is a file part of a multipart request body.
+type FormFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Reader      io.Reader
+}
+
+// MultipartBody is a request body sent as multipart/form-data instead of JSON.
+type MultipartBody struct {
+	Fields map[string]string
+	Files  []FormFile
+}
+
+// encode writes the multipart form and returns it with its content type.
+func (m *MultipartBody) encode() (io.Reader, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for key, value := range m.Fields {
+		if err := w.WriteField(key, value); err != nil {
+			return nil, "", fmt.Errorf("failed to write form field %s: %w", key, err)
+		}
+	}
+	for _, f := range m.Files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(f.FieldName), quoteEscaper.Replace(f.FileName)))
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header.Set("Content-Type", contentType)
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create form file %s: %w", f.FieldName, err)
+		}
+		if _, err = io.Copy(part, f.Reader); err != nil {
+			return nil, "", fmt.Errorf("failed to write form file %s: %w", f.FieldName, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to close multipart body: %w", err)
+	}
+	return &buf, w.FormDataContentType(), nil
+}
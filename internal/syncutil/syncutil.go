@@ -0,0 +1,147 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package syncutil provides the reports, pagination and bounded concurrency shared by the packages
+// that sync local sources to dify, such as annotationsync and kbsync.
+package syncutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	// PageSize is the number of items fetched per list request.
+	PageSize = 100
+	// DefaultConcurrency is the number of concurrent requests when none is configured.
+	DefaultConcurrency = 4
+)
+
+// Action is the change applied to a single item.
+type Action string
+
+// Actions.
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Operation is a planned or applied change to a single item.
+type Operation interface {
+	// Describe returns the action of the operation, the item it changes as shown in reports, and
+	// the error that prevented applying it.
+	Describe() (action Action, item string, err error)
+}
+
+// Report summarizes a sync.
+type Report[Op Operation] struct {
+	DryRun     bool
+	Operations []Op
+	Unchanged  int
+}
+
+// Count returns the number of operations with the given action.
+func (r *Report[Op]) Count(action Action) int {
+	var n int
+	for _, op := range r.Operations {
+		if a, _, _ := op.Describe(); a == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the operations that could not be applied.
+func (r *Report[Op]) Failed() []Op {
+	var failed []Op
+	for _, op := range r.Operations {
+		if _, _, err := op.Describe(); err != nil {
+			failed = append(failed, op)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of the failed operations, or returns nil.
+func (r *Report[Op]) Err() error {
+	var errs []error
+	for _, op := range r.Failed() {
+		action, item, err := op.Describe()
+		errs = append(errs, fmt.Errorf("%s %s: %w", action, item, err))
+	}
+	return errors.Join(errs...)
+}
+
+// WriteTo writes a human readable report listing every operation followed by a summary.
+func (r *Report[Op]) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, op := range r.Operations {
+		action, item, err := op.Describe()
+		status := ""
+		if err != nil {
+			status = " FAILED: " + err.Error()
+		}
+		fmt.Fprintf(&b, "%-6s %s%s\n", action, item, status)
+	}
+	prefix := ""
+	if r.DryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(&b, "%s%d created, %d updated, %d deleted, %d unchanged, %d failed\n", prefix,
+		r.Count(ActionCreate), r.Count(ActionUpdate), r.Count(ActionDelete), r.Unchanged, len(r.Failed()))
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// FetchAll retrieves every item of a paginated list. list returns a page of PageSize items,
+// numbered from 1, and whether more pages follow.
+func FetchAll[T any](ctx context.Context, list func(ctx context.Context, page, limit int) ([]T, bool, error)) ([]T, error) {
+	var items []T
+	for page := 1; ; page++ {
+		data, hasMore, err := list(ctx, page, PageSize)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data...)
+		if !hasMore || len(data) == 0 {
+			return items, nil
+		}
+	}
+}
+
+// Each calls fn for every index in [0, n), running at most concurrency calls at once.
+// Default concurrency: DefaultConcurrency. It returns the error of every call; calls that were not
+// started because ctx is done fail with the context error.
+func Each(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) []error {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	errs := make([]error, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range n {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, i)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
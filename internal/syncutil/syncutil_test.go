@@ -0,0 +1,127 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package syncutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOperation struct {
+	action Action
+	item   string
+	err    error
+}
+
+func (op testOperation) Describe() (Action, string, error) {
+	return op.action, op.item, op.err
+}
+
+func TestReport(t *testing.T) {
+	failure := errors.New("boom")
+	report := &Report[testOperation]{
+		Operations: []testOperation{
+			{action: ActionCreate, item: "a"},
+			{action: ActionUpdate, item: "b", err: failure},
+			{action: ActionCreate, item: "c"},
+		},
+		Unchanged: 2,
+	}
+
+	assert.Equal(t, 2, report.Count(ActionCreate))
+	assert.Equal(t, 0, report.Count(ActionDelete))
+	assert.Equal(t, []testOperation{report.Operations[1]}, report.Failed())
+	assert.ErrorIs(t, report.Err(), failure)
+	assert.EqualError(t, report.Err(), "update b: boom")
+
+	var out bytes.Buffer
+	_, err := report.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Equal(t, `create a
+update b FAILED: boom
+create c
+2 created, 1 updated, 0 deleted, 2 unchanged, 1 failed
+`, out.String())
+
+	report = &Report[testOperation]{DryRun: true}
+	assert.NoError(t, report.Err())
+	out.Reset()
+	_, err = report.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Equal(t, "dry run: 0 created, 0 updated, 0 deleted, 0 unchanged, 0 failed\n", out.String())
+}
+
+func TestFetchAll(t *testing.T) {
+	items := make([]int, 2*PageSize+1)
+	for i := range items {
+		items[i] = i
+	}
+	var calls int
+	got, err := FetchAll(t.Context(), func(_ context.Context, page, limit int) ([]int, bool, error) {
+		calls++
+		data, hasMore := paginate(items, page, limit)
+		return data, hasMore, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, items, got)
+	assert.Equal(t, 3, calls)
+
+	failure := errors.New("boom")
+	_, err = FetchAll(t.Context(), func(context.Context, int, int) ([]int, bool, error) {
+		return nil, false, failure
+	})
+	assert.ErrorIs(t, err, failure)
+}
+
+func TestEach(t *testing.T) {
+	var running, peak atomic.Int32
+	errs := Each(t.Context(), 10, 2, func(_ context.Context, i int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if i%3 == 0 {
+			return errors.New(strconv.Itoa(i))
+		}
+		return nil
+	})
+	require.Len(t, errs, 10)
+	for i, err := range errs {
+		if i%3 == 0 {
+			assert.EqualError(t, err, strconv.Itoa(i))
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	errs = Each(ctx, 3, 1, func(context.Context, int) error { return nil })
+	for _, err := range errs {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+// paginate returns the page of items selected by page and limit, as a list endpoint does, and
+// whether more pages follow.
+func paginate[T any](items []T, page, limit int) ([]T, bool) {
+	if page < 1 || limit < 1 {
+		return nil, false
+	}
+	start := min((page-1)*limit, len(items))
+	end := min(start+limit, len(items))
+	return items[start:end], end < len(items)
+}
@@ -0,0 +1,7 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package kbsync mirrors a local directory into a dify knowledge base. Each file becomes a document
+// whose relative path and content hash are stored in metadata fields, so that repeated syncs only
+// upload the files that changed.
+package kbsync
@@ -0,0 +1,134 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kbsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// File is a local file to mirror.
+type File struct {
+	// Path is the slash separated path of the file relative to the synced directory.
+	Path string
+	// FullPath is the path of the file on disk.
+	FullPath string
+	// Hash is the hex encoded SHA-256 of the file content.
+	Hash string
+}
+
+// Scan walks dir and hashes the files matching include and not matching exclude. Every file matches
+// an empty include list. Hidden files and directories, whose name starts with a dot, are skipped.
+// See Match for the pattern syntax.
+func Scan(dir string, include, exclude []string) ([]File, error) {
+	for _, pattern := range slices.Concat(include, exclude) {
+		if err := validatePattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	var files []File
+	err := filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == dir {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(entry.Name(), ".") || matchAny(exclude, rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || (len(include) > 0 && !matchAny(include, rel)) {
+			return nil
+		}
+		hash, err := hashFile(name)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Path: rel, FullPath: name, Hash: hash})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Match reports whether the slash separated relative path name matches pattern. Patterns use the
+// syntax of path.Match for each path element, plus "**" which matches any number of elements.
+// A pattern without a slash is matched against the last element only, so "*.md" matches markdown
+// files in every directory.
+func Match(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := range len(name) + 1 {
+			if matchElems(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchElems(pattern[1:], name[1:])
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePattern(pattern string) error {
+	for _, elem := range strings.Split(pattern, "/") {
+		if _, err := path.Match(elem, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func hashFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,324 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kbsync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/internal/syncutil"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+const (
+	// DefaultPathField is the default metadata field holding the relative path of a document's file.
+	DefaultPathField = "source_path"
+	// DefaultHashField is the default metadata field holding the SHA-256 of a document's file.
+	DefaultHashField = "source_hash"
+	// builtInMetadataID is the ID of built-in metadata values, which cannot be assigned.
+	builtInMetadataID = "built-in"
+)
+
+// Client is the subset of the knowledge API used by the sync. It is implemented by *v1.DatasetService.
+type Client interface {
+	ListDocuments(ctx context.Context, datasetID string, query schema.DocumentListQuery) (schema.DocumentListResponse, error)
+	CreateDocumentByFile(ctx context.Context, datasetID string, req schema.CreateDocumentByFileRequest) (schema.DocumentResponse, error)
+	UpdateDocumentByFile(ctx context.Context, datasetID, documentID string, req schema.UpdateDocumentByFileRequest) (schema.DocumentResponse, error)
+	DeleteDocument(ctx context.Context, datasetID, documentID string) error
+	ListMetadataFields(ctx context.Context, datasetID string) (schema.MetadataFieldListResponse, error)
+	CreateMetadataField(ctx context.Context, datasetID string, req schema.CreateMetadataFieldRequest) (schema.MetadataField, error)
+	UpdateDocumentMetadata(ctx context.Context, datasetID string, operations []schema.DocumentMetadataOperation) error
+	WaitForIndexing(ctx context.Context, datasetID, batch string, opts v1.IndexingWaitOptions) ([]schema.IndexingStatus, error)
+}
+
+var _ Client = (*v1.DatasetService)(nil)
+
+// Action is the change applied to a single document.
+type Action = syncutil.Action
+
+// Actions.
+const (
+	ActionCreate = syncutil.ActionCreate
+	ActionUpdate = syncutil.ActionUpdate
+	ActionDelete = syncutil.ActionDelete
+)
+
+// Operation is a planned or applied change to a single document.
+type Operation struct {
+	Action Action
+	// Path is the relative path of the file, or of the deleted document's file.
+	Path string
+	// DocumentID is empty for planned creations.
+	DocumentID string
+	// Batch is the indexing batch of created and updated documents.
+	Batch string
+	Err   error
+
+	file     File
+	document schema.Document
+}
+
+// Describe returns the action, the path and the error of the operation.
+func (op Operation) Describe() (Action, string, error) {
+	return op.Action, op.Path, op.Err
+}
+
+// Options configures a sync.
+type Options struct {
+	// Include and Exclude filter the synced files, see Scan.
+	Include []string
+	Exclude []string
+	// Prune deletes the documents of files that no longer exist. Only documents created by a sync,
+	// which have the path metadata field, are deleted.
+	Prune bool
+	// DryRun plans the operations without applying them.
+	DryRun bool
+	// Concurrency is the maximum number of concurrent write requests. Default: 4
+	Concurrency int
+	// Settings are used to create documents. The process rule is also applied to updated documents.
+	// Default process rule: automatic
+	Settings schema.DocumentSettings
+	// PathField and HashField name the metadata fields used to track files.
+	// Default: DefaultPathField and DefaultHashField
+	PathField string
+	HashField string
+	// Wait waits for the created and updated documents to be indexed.
+	Wait bool
+	// Indexing configures the wait.
+	Indexing v1.IndexingWaitOptions
+}
+
+// Report summarizes a sync. Failed operations include the documents that failed to index.
+type Report = syncutil.Report[Operation]
+
+// FetchAll retrieves every document of a dataset, following pagination.
+func FetchAll(ctx context.Context, client Client, datasetID string) ([]schema.Document, error) {
+	return syncutil.FetchAll(ctx, func(ctx context.Context, page, limit int) ([]schema.Document, bool, error) {
+		resp, err := client.ListDocuments(ctx, datasetID, schema.DocumentListQuery{Page: page, Limit: limit})
+		return resp.Data, resp.HasMore, err
+	})
+}
+
+// Plan computes the operations that make documents match files. Documents are matched by the path
+// metadata field. A document without it is adopted by the file whose base name equals the document
+// name, when exactly one file and one document have that name.
+func Plan(documents []schema.Document, files []File, pathField, hashField string, prune bool) ([]Operation, int) {
+	byPath := make(map[string]schema.Document, len(documents))
+	unmanaged := make(map[string][]schema.Document)
+	var ops []Operation
+	for _, doc := range documents {
+		p := metadataValue(doc, pathField)
+		if p == "" {
+			unmanaged[doc.Name] = append(unmanaged[doc.Name], doc)
+			continue
+		}
+		if _, ok := byPath[p]; ok {
+			// Only the first document of a path is kept in sync; the others are duplicates.
+			if prune {
+				ops = append(ops, Operation{Action: ActionDelete, Path: p, DocumentID: doc.ID, document: doc})
+			}
+			continue
+		}
+		byPath[p] = doc
+	}
+	baseNames := make(map[string]int, len(files))
+	for _, f := range files {
+		baseNames[path.Base(f.Path)]++
+	}
+
+	var unchanged int
+	wanted := make(map[string]bool, len(files))
+	for _, f := range files {
+		wanted[f.Path] = true
+		doc, ok := byPath[f.Path]
+		if !ok {
+			name := path.Base(f.Path)
+			if candidates := unmanaged[name]; len(candidates) == 1 && baseNames[name] == 1 {
+				doc, ok = candidates[0], true
+			}
+		}
+		switch {
+		case !ok:
+			ops = append(ops, Operation{Action: ActionCreate, Path: f.Path, file: f})
+		case metadataValue(doc, hashField) != f.Hash || metadataValue(doc, pathField) != f.Path:
+			ops = append(ops, Operation{Action: ActionUpdate, Path: f.Path, DocumentID: doc.ID, file: f, document: doc})
+		default:
+			unchanged++
+		}
+	}
+
+	if prune {
+		for _, doc := range documents {
+			p := metadataValue(doc, pathField)
+			if p != "" && !wanted[p] && byPath[p].ID == doc.ID {
+				ops = append(ops, Operation{Action: ActionDelete, Path: p, DocumentID: doc.ID, document: doc})
+			}
+		}
+	}
+	return ops, unchanged
+}
+
+// Sync makes the documents of a dataset match the files of dir and reports every operation. It is
+// idempotent: unchanged files are not uploaded again. Failed operations, including documents that
+// failed to index, are recorded in the report and returned joined.
+func Sync(ctx context.Context, client Client, datasetID, dir string, opts Options) (*Report, error) {
+	if opts.PathField == "" {
+		opts.PathField = DefaultPathField
+	}
+	if opts.HashField == "" {
+		opts.HashField = DefaultHashField
+	}
+	if opts.Settings.ProcessRule == nil {
+		opts.Settings.ProcessRule = &schema.ProcessRule{Mode: schema.ProcessModeAutomatic}
+	}
+
+	files, err := Scan(dir, opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := FetchAll(ctx, client, datasetID)
+	if err != nil {
+		return nil, err
+	}
+	ops, unchanged := Plan(documents, files, opts.PathField, opts.HashField, opts.Prune)
+	report := &Report{DryRun: opts.DryRun, Operations: ops, Unchanged: unchanged}
+	if opts.DryRun || len(ops) == 0 {
+		return report, nil
+	}
+
+	fields, err := ensureFields(ctx, client, datasetID, opts.PathField, opts.HashField)
+	if err != nil {
+		return nil, err
+	}
+	s := &syncer{client: client, datasetID: datasetID, opts: opts, fields: fields}
+	s.applyAll(ctx, report.Operations, s.apply)
+	if opts.Wait {
+		s.applyAll(ctx, report.Operations, s.wait)
+	}
+	return report, report.Err()
+}
+
+// ensureFields returns the metadata fields with the given names, creating the missing ones.
+func ensureFields(ctx context.Context, client Client, datasetID string, names ...string) (map[string]schema.MetadataField, error) {
+	resp, err := client.ListMetadataFields(ctx, datasetID)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]schema.MetadataField, len(names))
+	for _, field := range resp.DocMetadata {
+		fields[field.Name] = field
+	}
+	for _, name := range names {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+		req := schema.CreateMetadataFieldRequest{Type: schema.MetadataTypeString, Name: name}
+		field, err := client.CreateMetadataField(ctx, datasetID, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metadata field %q: %w", name, err)
+		}
+		fields[name] = field
+	}
+	return fields, nil
+}
+
+type syncer struct {
+	client    Client
+	datasetID string
+	opts      Options
+	fields    map[string]schema.MetadataField
+}
+
+// applyAll runs fn on every operation that has not failed yet, with bounded concurrency.
+func (s *syncer) applyAll(ctx context.Context, ops []Operation, fn func(context.Context, *Operation) error) {
+	var pending []int
+	for i := range ops {
+		if ops[i].Err == nil {
+			pending = append(pending, i)
+		}
+	}
+	errs := syncutil.Each(ctx, len(pending), s.opts.Concurrency, func(ctx context.Context, j int) error {
+		return fn(ctx, &ops[pending[j]])
+	})
+	for j, err := range errs {
+		ops[pending[j]].Err = err
+	}
+}
+
+func (s *syncer) apply(ctx context.Context, op *Operation) error {
+	if op.Action == ActionDelete {
+		return s.client.DeleteDocument(ctx, s.datasetID, op.DocumentID)
+	}
+
+	f, err := os.Open(op.file.FullPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var resp schema.DocumentResponse
+	if op.Action == ActionCreate {
+		resp, err = s.client.CreateDocumentByFile(ctx, s.datasetID, schema.CreateDocumentByFileRequest{
+			File:             f,
+			FileName:         op.file.Path,
+			DocumentSettings: s.opts.Settings,
+		})
+	} else {
+		resp, err = s.client.UpdateDocumentByFile(ctx, s.datasetID, op.DocumentID, schema.UpdateDocumentByFileRequest{
+			File:        f,
+			FileName:    op.file.Path,
+			ProcessRule: s.opts.Settings.ProcessRule,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	op.DocumentID, op.Batch = resp.Document.ID, resp.Batch
+
+	metadata := s.metadata(op)
+	err = s.client.UpdateDocumentMetadata(ctx, s.datasetID, []schema.DocumentMetadataOperation{metadata})
+	if err != nil {
+		return fmt.Errorf("failed to set metadata: %w", err)
+	}
+	return nil
+}
+
+// metadata returns the metadata of an uploaded document: its previous custom values with the path
+// and hash fields set to those of the file.
+func (s *syncer) metadata(op *Operation) schema.DocumentMetadataOperation {
+	values := make([]schema.MetadataValue, 0, len(op.document.DocMetadata)+2)
+	for _, m := range op.document.DocMetadata {
+		if m.ID == builtInMetadataID || m.Name == s.opts.PathField || m.Name == s.opts.HashField {
+			continue
+		}
+		values = append(values, schema.MetadataValue{ID: m.ID, Name: m.Name, Value: m.Value})
+	}
+	for name, value := range map[string]string{s.opts.PathField: op.file.Path, s.opts.HashField: op.file.Hash} {
+		field := s.fields[name]
+		values = append(values, schema.MetadataValue{ID: field.ID, Name: field.Name, Value: value})
+	}
+	return schema.DocumentMetadataOperation{DocumentID: op.DocumentID, MetadataList: values}
+}
+
+func (s *syncer) wait(ctx context.Context, op *Operation) error {
+	if op.Batch == "" {
+		return nil
+	}
+	_, err := s.client.WaitForIndexing(ctx, s.datasetID, op.Batch, s.opts.Indexing)
+	return err
+}
+
+func metadataValue(doc schema.Document, name string) string {
+	for _, m := range doc.DocMetadata {
+		if m.Name == name {
+			if s, ok := m.Value.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kbsync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+type fakeClient struct {
+	mu        sync.Mutex
+	documents []schema.Document
+	fields    []schema.MetadataField
+	contents  map[string]string
+	nextID    int
+}
+
+func (f *fakeClient) ListDocuments(_ context.Context, _ string, q schema.DocumentListQuery) (schema.DocumentListResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, hasMore := paginate(f.documents, q.Page, q.Limit)
+	return schema.DocumentListResponse{Data: data, HasMore: hasMore}, nil
+}
+
+func (f *fakeClient) CreateDocumentByFile(_ context.Context, _ string, req schema.CreateDocumentByFileRequest) (schema.DocumentResponse, error) {
+	data, err := io.ReadAll(req.File)
+	if err != nil {
+		return schema.DocumentResponse{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	doc := schema.Document{ID: "doc-" + strconv.Itoa(f.nextID), Name: filepath.Base(req.FileName)}
+	f.documents = append(f.documents, doc)
+	f.contents[doc.ID] = string(data)
+	return schema.DocumentResponse{Document: doc, Batch: "batch-" + doc.ID}, nil
+}
+
+func (f *fakeClient) UpdateDocumentByFile(_ context.Context, _, id string, req schema.UpdateDocumentByFileRequest) (schema.DocumentResponse, error) {
+	data, err := io.ReadAll(req.File)
+	if err != nil {
+		return schema.DocumentResponse{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.contents[id] = string(data)
+	return schema.DocumentResponse{Document: schema.Document{ID: id}, Batch: "batch-" + id}, nil
+}
+
+func (f *fakeClient) DeleteDocument(_ context.Context, _, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, doc := range f.documents {
+		if doc.ID == id {
+			f.documents = append(f.documents[:i], f.documents[i+1:]...)
+			break
+		}
+	}
+	delete(f.contents, id)
+	return nil
+}
+
+func (f *fakeClient) ListMetadataFields(context.Context, string) (schema.MetadataFieldListResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return schema.MetadataFieldListResponse{DocMetadata: f.fields}, nil
+}
+
+func (f *fakeClient) CreateMetadataField(_ context.Context, _ string, req schema.CreateMetadataFieldRequest) (schema.MetadataField, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	field := schema.MetadataField{ID: "field-" + req.Name, Name: req.Name, Type: req.Type}
+	f.fields = append(f.fields, field)
+	return field, nil
+}
+
+func (f *fakeClient) UpdateDocumentMetadata(_ context.Context, _ string, ops []schema.DocumentMetadataOperation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, op := range ops {
+		for i, doc := range f.documents {
+			if doc.ID != op.DocumentID {
+				continue
+			}
+			f.documents[i].DocMetadata = nil
+			for _, m := range op.MetadataList {
+				f.documents[i].DocMetadata = append(f.documents[i].DocMetadata,
+					schema.DocumentMetadata{ID: m.ID, Name: m.Name, Type: schema.MetadataTypeString, Value: m.Value})
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeClient) WaitForIndexing(context.Context, string, string, v1.IndexingWaitOptions) ([]schema.IndexingStatus, error) {
+	return nil, nil
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o600))
+	}
+	write("guide/intro.md", "intro")
+	write("guide/setup.md", "setup")
+	write("drafts/wip.md", "wip")
+	write(".git/HEAD", "ref")
+
+	client := &fakeClient{contents: map[string]string{}}
+	opts := Options{Exclude: []string{"drafts/**"}, Prune: true, Wait: true}
+
+	report, err := Sync(t.Context(), client, "ds", dir, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Count(ActionCreate))
+	assert.Len(t, client.documents, 2)
+
+	report, err = Sync(t.Context(), client, "ds", dir, opts)
+	require.NoError(t, err)
+	assert.Empty(t, report.Operations)
+	assert.Equal(t, 2, report.Unchanged)
+
+	write("guide/setup.md", "setup v2")
+	require.NoError(t, os.Remove(filepath.Join(dir, "guide", "intro.md")))
+	report, err = Sync(t.Context(), client, "ds", dir, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Count(ActionUpdate))
+	assert.Equal(t, 1, report.Count(ActionDelete))
+	require.Len(t, client.documents, 1)
+	assert.Equal(t, "setup v2", client.contents[client.documents[0].ID])
+	assert.Equal(t, "guide/setup.md", metadataValue(client.documents[0], DefaultPathField))
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.md", "a/b/c.md", true},
+		{"*.md", "c.txt", false},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/sub/a.md", false},
+		{"docs/**/*.md", "docs/a.md", true},
+		{"docs/**/*.md", "docs/sub/deep/a.md", true},
+		{"docs/**", "docs", true},
+		{"**/internal/*", "a/internal/x", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.pattern, tt.name), "%s ~ %s", tt.pattern, tt.name)
+	}
+}
+
+// paginate returns the page of items selected by page and limit, as a list endpoint does, and
+// whether more pages follow.
+func paginate[T any](items []T, page, limit int) ([]T, bool) {
+	if page < 1 || limit < 1 {
+		return nil, false
+	}
+	start := min((page-1)*limit, len(items))
+	end := min(start+limit, len(items))
+	return items[start:end], end < len(items)
+}
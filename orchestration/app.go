@@ -0,0 +1,127 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// App is called by a step with the inputs mapped by the step and returns its outputs.
+type App interface {
+	Call(ctx context.Context, inputs map[string]any) (map[string]any, error)
+}
+
+// AppFunc adapts a function to an App, for steps written in Go.
+type AppFunc func(ctx context.Context, inputs map[string]any) (map[string]any, error)
+
+// Call implements App.
+func (f AppFunc) Call(ctx context.Context, inputs map[string]any) (map[string]any, error) {
+	return f(ctx, inputs)
+}
+
+// WorkflowRunner executes workflows. It is implemented by *v1.WorkflowService.
+type WorkflowRunner interface {
+	Run(ctx context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error)
+}
+
+// ChatClient sends chat messages. It is implemented by *v1.ChatService.
+type ChatClient interface {
+	SendMessage(ctx context.Context, req schema.ChatMessageRequest) (schema.MessageResponse, error)
+}
+
+// CompletionClient sends completion messages. It is implemented by *v1.CompletionService.
+type CompletionClient interface {
+	SendMessage(ctx context.Context, req schema.CompletionMessageRequest) (schema.MessageResponse, error)
+}
+
+var (
+	_ WorkflowRunner   = (*v1.WorkflowService)(nil)
+	_ ChatClient       = (*v1.ChatService)(nil)
+	_ CompletionClient = (*v1.CompletionService)(nil)
+)
+
+// Workflow returns an app running a workflow as user. The outputs of the app are the outputs of
+// the run, and runs that do not succeed fail with a *v1.WorkflowRunError.
+func Workflow(w WorkflowRunner, user string) App {
+	return AppFunc(func(ctx context.Context, inputs map[string]any) (map[string]any, error) {
+		data, err := marshalInputs(inputs)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := w.Run(ctx, schema.RunWorkflowRequest{Inputs: data, ResponseMode: v1.BlockingMode, User: user})
+		if err != nil {
+			return nil, err
+		}
+		if resp.Data.Status != schema.WorkflowStatusSucceeded {
+			return nil, &v1.WorkflowRunError{RunID: resp.WorkflowRunID, Status: resp.Data.Status, Message: resp.Data.Error}
+		}
+		return resp.Data.Outputs, nil
+	})
+}
+
+// Chat returns an app sending a message to a chat app as user. The query input is the message,
+// the optional conversation_id input continues a conversation and the other inputs are the app
+// inputs. The outputs are answer, conversation_id and message_id.
+func Chat(c ChatClient, user string) App {
+	return AppFunc(func(ctx context.Context, inputs map[string]any) (map[string]any, error) {
+		inputs = maps.Clone(inputs)
+		query, ok := inputs["query"].(string)
+		if !ok {
+			return nil, fmt.Errorf("query input is %s, not a string", describe(inputs["query"]))
+		}
+		conversationID, _ := inputs["conversation_id"].(string)
+		delete(inputs, "query")
+		delete(inputs, "conversation_id")
+		data, err := marshalInputs(inputs)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.SendMessage(ctx, schema.ChatMessageRequest{
+			Query:          query,
+			Inputs:         data,
+			ResponseMode:   v1.BlockingMode,
+			User:           user,
+			ConversationID: conversationID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"answer":          resp.Answer,
+			"conversation_id": resp.ConversationID,
+			"message_id":      resp.MessageID,
+		}, nil
+	})
+}
+
+// Completion returns an app sending a message to a text generation app as user. The inputs are
+// the app inputs and the outputs are answer and message_id.
+func Completion(c CompletionClient, user string) App {
+	return AppFunc(func(ctx context.Context, inputs map[string]any) (map[string]any, error) {
+		data, err := marshalInputs(inputs)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.SendMessage(ctx, schema.CompletionMessageRequest{Inputs: data, ResponseMode: v1.BlockingMode, User: user})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"answer": resp.Answer, "message_id": resp.MessageID}, nil
+	})
+}
+
+// marshalInputs encodes the inputs of an app. Nil inputs are sent as an empty object, because
+// dify rejects null inputs.
+func marshalInputs(inputs map[string]any) ([]byte, error) {
+	if inputs == nil {
+		inputs = map[string]any{}
+	}
+	return json.Marshal(inputs)
+}
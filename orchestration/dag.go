@@ -0,0 +1,477 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/internal/syncutil"
+)
+
+const (
+	// defaultBackoff is the delay before the first retry when Step.Backoff is unset.
+	defaultBackoff = time.Second
+	// maxBackoff bounds the delay between retries, unless Step.Backoff is longer.
+	maxBackoff = time.Minute
+
+	// nameInput is the name of the run input in expressions, and nameItem and nameIndex the
+	// names of the element of a ForEach step and its index.
+	nameInput = "input"
+	nameItem  = "item"
+	nameIndex = "index"
+)
+
+var stepName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Step is a step of a DAG, calling an app.
+//
+// Expressions reference the run input as input and the outputs of a prior step by its name, for
+// instance extract.title or classify.labels[0]. They support string, number, boolean and null
+// literals, the ==, !=, <, <=, >, >=, !, && and || operators, and a ?? b, which is a unless a is
+// null. Missing keys and the outputs of skipped steps are null.
+type Step struct {
+	// Name identifies the step in expressions and in the trace.
+	Name string
+	App  App
+	// Inputs maps the inputs of the app to expressions.
+	Inputs map[string]string
+	// After lists the steps that must finish before this one, in addition to the steps its
+	// expressions reference.
+	After []string
+	// If is an expression skipping the step unless it is true. A step is also skipped when all
+	// its dependencies are skipped, so that a whole branch is skipped by the condition of its
+	// first step.
+	If string
+	// ForEach is an expression evaluating to a list. The app is called once per element, in
+	// parallel, and the input expressions may reference the element as item and its position as
+	// index. The outputs of the step are {"results": [...]}, the outputs of every call in order.
+	ForEach string
+	// MaxAttempts is the number of attempts of every call. Default: 1
+	MaxAttempts int
+	// Retry reports whether a failed attempt is retried. Default: v1.IsTransient, which retries
+	// network errors, including timeouts, HTTP 429 and server errors
+	Retry func(err error) bool
+	// Backoff is the delay before the first retry; it doubles on every retry up to a minute.
+	// Default: 1 second
+	Backoff time.Duration
+	// Timeout bounds every attempt. Zero is unlimited.
+	Timeout time.Duration
+}
+
+// Options configures a DAG.
+type Options struct {
+	// Concurrency is the maximum number of concurrent app calls. Default: 4
+	Concurrency int
+}
+
+// DAG is a directed acyclic graph of steps. It is safe for concurrent runs.
+type DAG struct {
+	opts  Options
+	tasks []*task
+}
+
+// task is a compiled step.
+type task struct {
+	Step
+	inputs  map[string]*expr
+	cond    *expr
+	forEach *expr
+	deps    []int
+}
+
+// New checks and compiles the steps of a DAG.
+func New(steps []Step, opts Options) (*DAG, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = syncutil.DefaultConcurrency
+	}
+	index := make(map[string]int, len(steps))
+	for i, step := range steps {
+		switch {
+		case !stepName.MatchString(step.Name):
+			return nil, fmt.Errorf("invalid step name %q", step.Name)
+		case slices.Contains([]string{nameInput, nameItem, nameIndex, "true", "false", "null"}, step.Name):
+			return nil, fmt.Errorf("step name %s is reserved", step.Name)
+		case step.App == nil:
+			return nil, fmt.Errorf("step %s: app is required", step.Name)
+		}
+		if _, ok := index[step.Name]; ok {
+			return nil, fmt.Errorf("duplicate step %s", step.Name)
+		}
+		index[step.Name] = i
+	}
+
+	d := &DAG{opts: opts}
+	for _, step := range steps {
+		t, err := compile(step, index)
+		if err != nil {
+			return nil, fmt.Errorf("step %s: %w", step.Name, err)
+		}
+		d.tasks = append(d.tasks, t)
+	}
+	if err := d.checkCycles(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func compile(step Step, index map[string]int) (*task, error) {
+	if step.MaxAttempts <= 0 {
+		step.MaxAttempts = 1
+	}
+	if step.Backoff <= 0 {
+		step.Backoff = defaultBackoff
+	}
+	if step.Retry == nil {
+		step.Retry = v1.IsTransient
+	}
+	t := &task{Step: step, inputs: make(map[string]*expr, len(step.Inputs))}
+
+	var deps []string
+	parse := func(src string, local bool) (*expr, error) {
+		e, err := parseExpr(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range e.refs {
+			switch {
+			case ref == nameInput:
+			case ref == nameItem || ref == nameIndex:
+				if !local {
+					return nil, fmt.Errorf("expression %q: %s is only defined in the inputs of ForEach steps", src, ref)
+				}
+			case ref == step.Name:
+				return nil, fmt.Errorf("expression %q: step references itself", src)
+			default:
+				if _, ok := index[ref]; !ok {
+					return nil, fmt.Errorf("expression %q: unknown step %s", src, ref)
+				}
+				deps = append(deps, ref)
+			}
+		}
+		return e, nil
+	}
+
+	var err error
+	for name, src := range step.Inputs {
+		if t.inputs[name], err = parse(src, step.ForEach != ""); err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+	}
+	if step.If != "" {
+		if t.cond, err = parse(step.If, false); err != nil {
+			return nil, fmt.Errorf("condition: %w", err)
+		}
+	}
+	if step.ForEach != "" {
+		if t.forEach, err = parse(step.ForEach, false); err != nil {
+			return nil, fmt.Errorf("for each: %w", err)
+		}
+	}
+	for _, name := range step.After {
+		if _, ok := index[name]; !ok || name == step.Name {
+			return nil, fmt.Errorf("invalid dependency %s", name)
+		}
+		deps = append(deps, name)
+	}
+
+	for _, name := range deps {
+		if !slices.Contains(t.deps, index[name]) {
+			t.deps = append(t.deps, index[name])
+		}
+	}
+	slices.Sort(t.deps)
+	return t, nil
+}
+
+// checkCycles returns an error if the dependencies of the steps form a cycle.
+func (d *DAG) checkCycles() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(d.tasks))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		path = append(path, d.tasks[i].Name)
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("dependency cycle %v", path)
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, dep := range d.tasks[i].deps {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range d.tasks {
+		if err := visit(i, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run runs the DAG with an input, running every step once its dependencies finished. It returns
+// the trace of the run, and a *StepError if a step failed, which cancels the other steps.
+func (d *DAG) Run(ctx context.Context, input map[string]any) (*Trace, error) {
+	trace := &Trace{Input: input, Started: time.Now(), Steps: make([]StepTrace, len(d.tasks))}
+	for i, t := range d.tasks {
+		trace.Steps[i] = StepTrace{Name: t.Name, Status: StatusCanceled}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r := &run{
+		dag:    d,
+		trace:  trace,
+		cancel: cancel,
+		sem:    make(chan struct{}, d.opts.Concurrency),
+		env:    map[string]any{nameInput: input},
+		done:   make([]chan struct{}, len(d.tasks)),
+	}
+	for i := range r.done {
+		r.done[i] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for i := range d.tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(r.done[i])
+			r.step(ctx, i)
+		}()
+	}
+	wg.Wait()
+
+	trace.Finished = time.Now()
+	switch {
+	case r.err != nil:
+		trace.Status, trace.Error = StatusFailed, r.err.Error()
+		return trace, r.err
+	case !r.finished():
+		// Only the context of the caller cancels a run without failure.
+		err := ctx.Err()
+		trace.Status, trace.Error = StatusCanceled, err.Error()
+		return trace, err
+	}
+	trace.Status = StatusSucceeded
+	return trace, nil
+}
+
+// run is the state of a run.
+type run struct {
+	dag    *DAG
+	trace  *Trace
+	cancel context.CancelFunc
+	sem    chan struct{}
+	done   []chan struct{}
+
+	mu  sync.Mutex
+	env map[string]any // the run input and the outputs of the finished steps
+	err error
+}
+
+// finished reports whether all steps finished.
+func (r *run) finished() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, step := range r.trace.Steps {
+		if step.Status == StatusCanceled {
+			return false
+		}
+	}
+	return true
+}
+
+// step waits for the dependencies of a step and runs it.
+func (r *run) step(ctx context.Context, i int) {
+	t := r.dag.tasks[i]
+	for _, dep := range t.deps {
+		select {
+		case <-r.done[dep]:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	r.mu.Lock()
+	skipped := len(t.deps) > 0
+	for _, dep := range t.deps {
+		switch r.trace.Steps[dep].Status {
+		case StatusSkipped:
+		case StatusSucceeded:
+			skipped = false
+		default:
+			r.mu.Unlock()
+			return
+		}
+	}
+	env := maps.Clone(r.env)
+	r.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+
+	trace := StepTrace{Name: t.Name, Status: StatusSkipped}
+	if !skipped && t.cond != nil {
+		ok, err := t.cond.evalBool(env)
+		if err != nil {
+			r.finish(i, trace, err)
+			return
+		}
+		skipped = !ok
+	}
+	if skipped {
+		r.finish(i, trace, nil)
+		return
+	}
+
+	trace.Started = time.Now()
+	outputs, calls, err := r.call(ctx, t, env)
+	trace.Finished = time.Now()
+	trace.Status, trace.Outputs, trace.Calls = StatusSucceeded, outputs, calls
+	if ctx.Err() != nil && err != nil {
+		trace.Status, trace.Error = StatusCanceled, err.Error()
+		r.finish(i, trace, nil)
+		return
+	}
+	r.finish(i, trace, err)
+}
+
+// call calls the app of a step, once per element for ForEach steps.
+func (r *run) call(ctx context.Context, t *task, env map[string]any) (map[string]any, []Call, error) {
+	if t.forEach == nil {
+		calls := make([]Call, 1)
+		outputs, err := r.callOnce(ctx, t, env, &calls[0])
+		return outputs, calls, err
+	}
+
+	v, err := t.forEach.eval(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	var items []any
+	if v != nil {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, nil, fmt.Errorf("expression %q: %s is not a list", t.ForEach, describe(v))
+		}
+		for i := range rv.Len() {
+			items = append(items, rv.Index(i).Interface())
+		}
+	}
+
+	calls := make([]Call, len(items))
+	results := make([]any, len(items))
+	errs := make([]error, len(items))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := maps.Clone(env)
+			local[nameItem], local[nameIndex] = item, i
+			outputs, err := r.callOnce(ctx, t, local, &calls[i])
+			results[i], errs[i] = outputs, err
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, calls, err
+	}
+	return map[string]any{"results": results}, calls, nil
+}
+
+// callOnce maps the inputs of a call and calls the app with retries.
+func (r *run) callOnce(ctx context.Context, t *task, env map[string]any, call *Call) (map[string]any, error) {
+	call.Inputs = make(map[string]any, len(t.inputs))
+	for name, e := range t.inputs {
+		v, err := e.eval(env)
+		if err != nil {
+			call.Error = err.Error()
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		call.Inputs[name] = v
+	}
+
+	backoff := t.Backoff
+	for attempt := 1; ; attempt++ {
+		outputs, err := r.attempt(ctx, t, call)
+		if err == nil {
+			call.Outputs = outputs
+			return outputs, nil
+		}
+		if attempt >= t.MaxAttempts || ctx.Err() != nil || !t.Retry(err) {
+			call.Error = err.Error()
+			return nil, err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			call.Error = err.Error()
+			return nil, err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, max(maxBackoff, t.Backoff))
+	}
+}
+
+// attempt calls the app once, within the concurrency limit.
+func (r *run) attempt(ctx context.Context, t *task, call *Call) (map[string]any, error) {
+	select {
+	case r.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-r.sem }()
+
+	if t.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+		defer cancel()
+	}
+	a := Attempt{Started: time.Now()}
+	outputs, err := t.App.Call(ctx, maps.Clone(call.Inputs))
+	a.Finished = time.Now()
+	if err != nil {
+		a.Error = err.Error()
+	}
+	call.Attempts = append(call.Attempts, a)
+	return outputs, err
+}
+
+// finish records the trace of a finished step. A failure cancels the run.
+func (r *run) finish(i int, trace StepTrace, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		trace.Status, trace.Error = StatusFailed, err.Error()
+		if r.err == nil {
+			r.err = &StepError{Step: trace.Name, Err: err}
+			r.cancel()
+		}
+	}
+	r.trace.Steps[i] = trace
+	if trace.Status == StatusSucceeded {
+		r.env[trace.Name] = trace.Outputs
+	} else {
+		r.env[trace.Name] = nil
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestExpr(t *testing.T) {
+	env := map[string]any{
+		"input":   map[string]any{"text": "hello", "count": 3},
+		"extract": map[string]any{"labels": []any{"a", "b"}, "score": 0.75, "meta": map[string]any{"lang": "en"}},
+		"skipped": nil,
+	}
+	tests := []struct {
+		src  string
+		want any
+	}{
+		{`input.text`, "hello"},
+		{`extract.labels[1]`, "b"},
+		{`extract["meta"].lang`, "en"},
+		{`extract.labels[5]`, nil},
+		{`skipped.answer ?? input.text`, "hello"},
+		{`input.count == 3 && extract.score >= 0.5`, true},
+		{`!(input.text == "hello") || extract.meta.lang != "en"`, false},
+		{`input.text < "world"`, true},
+		{`null ?? -1.5`, -1.5},
+	}
+	for _, tt := range tests {
+		e, err := parseExpr(tt.src)
+		require.NoError(t, err, tt.src)
+		got, err := e.eval(env)
+		require.NoError(t, err, tt.src)
+		assert.Equal(t, tt.want, got, tt.src)
+	}
+
+	for _, src := range []string{`input.`, `input.text ==`, `"open`, `(input`, `a b`, `input # 1`} {
+		_, err := parseExpr(src)
+		assert.Error(t, err, src)
+	}
+	for _, src := range []string{`input.text.length`, `input.text && true`, `input.text < 1`, `missing`} {
+		e, err := parseExpr(src)
+		require.NoError(t, err, src)
+		_, err = e.eval(env)
+		assert.Error(t, err, src)
+	}
+}
+
+type fakeWorkflow struct{}
+
+func (fakeWorkflow) Run(_ context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error) {
+	var inputs map[string]any
+	if err := json.Unmarshal(req.Inputs, &inputs); err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
+	text := inputs["text"].(string)
+	return schema.RunWorkflowResponse{Data: schema.RunWorkflowResponseData{
+		Status:  schema.WorkflowStatusSucceeded,
+		Outputs: map[string]any{"kind": "invoice", "lines": strings.Split(text, "\n")},
+	}}, nil
+}
+
+type fakeChat struct{}
+
+func (fakeChat) SendMessage(_ context.Context, req schema.ChatMessageRequest) (schema.MessageResponse, error) {
+	return schema.MessageResponse{Answer: "category of " + req.Query, ConversationID: "c", MessageID: "m"}, nil
+}
+
+type fakeCompletion struct{}
+
+func (fakeCompletion) SendMessage(_ context.Context, req schema.CompletionMessageRequest) (schema.MessageResponse, error) {
+	return schema.MessageResponse{Answer: "summary of " + string(req.Inputs)}, nil
+}
+
+func TestRun(t *testing.T) {
+	var flaky atomic.Int32
+	dag, err := New([]Step{
+		{Name: "extract", App: Workflow(fakeWorkflow{}, "abc"), Inputs: map[string]string{"text": "input.text"}},
+		{
+			Name:    "classify",
+			App:     Chat(fakeChat{}, "abc"),
+			ForEach: "extract.lines",
+			Inputs:  map[string]string{"query": "item", "position": "index"},
+		},
+		{
+			Name:        "invoice",
+			App:         Completion(fakeCompletion{}, "abc"),
+			If:          `extract.kind == "invoice"`,
+			Inputs:      map[string]string{"line": "classify.results[0].answer"},
+			MaxAttempts: 2,
+			Backoff:     time.Millisecond,
+		},
+		{Name: "receipt", App: Completion(fakeCompletion{}, "abc"), If: `extract.kind == "receipt"`},
+		{Name: "receipt_total", App: Completion(fakeCompletion{}, "abc"), After: []string{"receipt"}},
+		{
+			Name: "notify",
+			App: AppFunc(func(_ context.Context, inputs map[string]any) (map[string]any, error) {
+				if flaky.Add(1) == 1 {
+					return nil, errors.New("temporary failure")
+				}
+				return map[string]any{"sent": inputs["summary"]}, nil
+			}),
+			Inputs:      map[string]string{"summary": "receipt.answer ?? invoice.answer"},
+			MaxAttempts: 2,
+			Retry:       func(error) bool { return true },
+			Backoff:     time.Millisecond,
+		},
+	}, Options{})
+	require.NoError(t, err)
+
+	trace, err := dag.Run(t.Context(), map[string]any{"text": "line 1\nline 2"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, trace.Status)
+
+	statuses := map[string]string{}
+	for _, step := range trace.Steps {
+		statuses[step.Name] = step.Status
+	}
+	assert.Equal(t, map[string]string{
+		"extract":       StatusSucceeded,
+		"classify":      StatusSucceeded,
+		"invoice":       StatusSucceeded,
+		"receipt":       StatusSkipped,
+		"receipt_total": StatusSkipped,
+		"notify":        StatusSucceeded,
+	}, statuses)
+
+	classify, ok := trace.Step("classify")
+	require.True(t, ok)
+	require.Len(t, classify.Calls, 2)
+	assert.Equal(t, map[string]any{"query": "line 2", "position": 1}, classify.Calls[1].Inputs)
+	assert.Equal(t, "category of line 2", classify.Outputs["results"].([]any)[1].(map[string]any)["answer"])
+
+	notify, _ := trace.Step("notify")
+	assert.Len(t, notify.Calls[0].Attempts, 2)
+	assert.Equal(t, "temporary failure", notify.Calls[0].Attempts[0].Error)
+	assert.Equal(t, `summary of {"line":"category of line 1"}`, notify.Outputs["sent"])
+
+	_, err = json.Marshal(trace)
+	require.NoError(t, err)
+}
+
+func TestRunRetriesTransientErrors(t *testing.T) {
+	tests := []struct {
+		err      error
+		attempts int
+	}{
+		{&v1.APIError{StatusCode: http.StatusServiceUnavailable}, 3},
+		{&v1.APIError{StatusCode: http.StatusBadRequest, Code: "invalid_param"}, 1},
+		{errors.New("boom"), 1},
+	}
+	for _, tt := range tests {
+		dag, err := New([]Step{{
+			Name: "call",
+			App: AppFunc(func(context.Context, map[string]any) (map[string]any, error) {
+				return nil, tt.err
+			}),
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+		}}, Options{})
+		require.NoError(t, err)
+		trace, err := dag.Run(t.Context(), nil)
+		require.ErrorIs(t, err, tt.err)
+		step, _ := trace.Step("call")
+		assert.Len(t, step.Calls[0].Attempts, tt.attempts, tt.err.Error())
+	}
+}
+
+func TestAppNilInputs(t *testing.T) {
+	out, err := Completion(fakeCompletion{}, "abc").Call(t.Context(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "summary of {}", out["answer"])
+}
+
+func TestRunFailure(t *testing.T) {
+	failure := errors.New("boom")
+	dag, err := New([]Step{
+		{Name: "first", App: AppFunc(func(context.Context, map[string]any) (map[string]any, error) {
+			return nil, failure
+		})},
+		{Name: "second", App: AppFunc(func(context.Context, map[string]any) (map[string]any, error) {
+			return map[string]any{}, nil
+		}), After: []string{"first"}},
+	}, Options{})
+	require.NoError(t, err)
+
+	trace, err := dag.Run(t.Context(), nil)
+	var stepErr *StepError
+	require.ErrorAs(t, err, &stepErr)
+	assert.Equal(t, "first", stepErr.Step)
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, StatusFailed, trace.Status)
+	assert.Equal(t, StatusFailed, trace.Steps[0].Status)
+	assert.Equal(t, StatusCanceled, trace.Steps[1].Status)
+}
+
+func TestNew(t *testing.T) {
+	app := AppFunc(func(context.Context, map[string]any) (map[string]any, error) { return nil, nil })
+	tests := [][]Step{
+		{{Name: "a", App: app, Inputs: map[string]string{"x": "b.y"}}, {Name: "b", App: app, After: []string{"a"}}},
+		{{Name: "a", App: app, Inputs: map[string]string{"x": "unknown.y"}}},
+		{{Name: "a", App: app, Inputs: map[string]string{"x": "item"}}},
+		{{Name: "a", App: app, If: "a.done"}},
+		{{Name: "input", App: app}},
+		{{Name: "a-b", App: app}},
+		{{Name: "a", App: app}, {Name: "a", App: app}},
+		{{Name: "a"}},
+	}
+	for _, steps := range tests {
+		_, err := New(steps, Options{})
+		assert.Error(t, err, steps[0].Name)
+	}
+}
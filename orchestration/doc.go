@@ -0,0 +1,9 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package orchestration chains dify apps in a DAG declared in Go, for instance extract, classify
+// and summarize. Every step calls a workflow, chat or completion app, or a Go function, with inputs
+// mapped from the run input and prior outputs by expressions. Independent steps run in parallel,
+// steps can fan out over a list and be skipped by conditions, calls failing with transient errors
+// are retried, and every run returns a full execution trace.
+package orchestration
@@ -0,0 +1,487 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package orchestration
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// expr is a compiled expression. The grammar, from the lowest precedence:
+//
+//	expr    = and { ( "||" | "??" ) and }
+//	and     = not { "&&" not }
+//	not     = "!" not | cmp
+//	cmp     = primary [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) primary ]
+//	primary = string | number | "true" | "false" | "null" | path | "(" expr ")"
+//	path    = ident { "." ident | "[" ( number | string ) "]" }
+type expr struct {
+	src  string
+	root exprNode
+	// refs are the names the paths of the expression start with.
+	refs []string
+}
+
+type exprNode interface {
+	eval(env map[string]any) (any, error)
+}
+
+func parseExpr(src string) (*expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", src, err)
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", src, err)
+	}
+	return &expr{src: src, root: root, refs: p.refs}, nil
+}
+
+// eval evaluates the expression. Paths start with a name of env.
+func (e *expr) eval(env map[string]any) (any, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", e.src, err)
+	}
+	return v, nil
+}
+
+// evalBool evaluates an expression which must be a boolean.
+func (e *expr) evalBool(env map[string]any) (bool, error) {
+	v, err := e.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q: %s is not a boolean", e.src, describe(v))
+	}
+	return b, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	val  any
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "??", "<", ">", "!", ".", "[", "]", "(", ")"}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			s, err := strconv.Unquote(src[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", src[i:end+1])
+			}
+			toks = append(toks, token{kind: tokString, text: src[i : end+1], val: s})
+			i = end + 1
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			end := i + 1
+			for end < len(src) && (src[end] >= '0' && src[end] <= '9' || src[end] == '.') {
+				end++
+			}
+			n, err := strconv.ParseFloat(src[i:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s", src[i:end])
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:end], val: n})
+			i = end
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			for end < len(src) && (src[end] == '_' || unicode.IsLetter(rune(src[end])) || unicode.IsDigit(rune(src[end]))) {
+				end++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:end]})
+			i = end
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			toks = append(toks, token{kind: tokOp, text: op})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: tokEOF}), nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+	refs []string
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the operators ops.
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind == tokOp {
+		for _, op := range ops {
+			if t.text == op {
+				p.pos++
+				return op, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		return fmt.Errorf("expected %q, got %s", op, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (exprNode, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("||", "??")
+		if !ok {
+			return x, nil
+		}
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op: op, x: x, y: y}
+	}
+}
+
+func (p *parser) parseAnd() (exprNode, error) {
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return x, nil
+		}
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op: "&&", x: x, y: y}
+	}
+}
+
+func (p *parser) parseNot() (exprNode, error) {
+	if _, ok := p.accept("!"); ok {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &not{x: x}, nil
+	}
+	return p.parseCmp()
+}
+
+func (p *parser) parseCmp() (exprNode, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return x, nil
+	}
+	y, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return &binary{op: op, x: x, y: y}, nil
+}
+
+func (p *parser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString, tokNumber:
+		return literal{t.val}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		case "null":
+			return literal{nil}, nil
+		}
+		return p.parsePath(t.text)
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+func (p *parser) parsePath(name string) (exprNode, error) {
+	if !slices.Contains(p.refs, name) {
+		p.refs = append(p.refs, name)
+	}
+	n := &path{name: name}
+	for {
+		op, ok := p.accept(".", "[")
+		if !ok {
+			return n, nil
+		}
+		if op == "." {
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, fmt.Errorf("expected a name after \".\", got %s", t)
+			}
+			n.elems = append(n.elems, t.text)
+			continue
+		}
+		t := p.next()
+		if t.kind != tokString && t.kind != tokNumber {
+			return nil, fmt.Errorf("expected an index, got %s", t)
+		}
+		n.elems = append(n.elems, t.val)
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type literal struct {
+	v any
+}
+
+func (l literal) eval(map[string]any) (any, error) {
+	return l.v, nil
+}
+
+// path selects a value from env. Missing keys, out of range indexes and the outputs of
+// skipped steps evaluate to null.
+type path struct {
+	name  string
+	elems []any // string keys and float64 indexes
+}
+
+func (n *path) eval(env map[string]any) (any, error) {
+	v, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown name %s", n.name)
+	}
+	for _, elem := range n.elems {
+		if v == nil {
+			return nil, nil
+		}
+		switch elem := elem.(type) {
+		case string:
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+				return nil, fmt.Errorf("cannot select %s of %s", elem, describe(v))
+			}
+			item := rv.MapIndex(reflect.ValueOf(elem).Convert(rv.Type().Key()))
+			if !item.IsValid() {
+				return nil, nil
+			}
+			v = item.Interface()
+		case float64:
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return nil, fmt.Errorf("cannot index %s", describe(v))
+			}
+			i := int(elem)
+			if float64(i) != elem || i < 0 || i >= rv.Len() {
+				return nil, nil
+			}
+			v = rv.Index(i).Interface()
+		}
+	}
+	return v, nil
+}
+
+type not struct {
+	x exprNode
+}
+
+func (n *not) eval(env map[string]any) (any, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", describe(v))
+	}
+	return !b, nil
+}
+
+type binary struct {
+	op   string
+	x, y exprNode
+}
+
+func (n *binary) eval(env map[string]any) (any, error) {
+	x, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "??":
+		if x != nil {
+			return x, nil
+		}
+		return n.y.eval(env)
+	case "&&", "||":
+		b, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("operand of %s is %s, not a boolean", n.op, describe(x))
+		}
+		if b == (n.op == "||") {
+			return b, nil
+		}
+		y, err := n.y.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := y.(bool); !ok {
+			return nil, fmt.Errorf("operand of %s is %s, not a boolean", n.op, describe(y))
+		}
+		return y, nil
+	}
+
+	y, err := n.y.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(x, y), nil
+	case "!=":
+		return !equal(x, y), nil
+	}
+	c, err := compare(x, y)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func equal(x, y any) bool {
+	if a, ok := toNumber(x); ok {
+		b, ok := toNumber(y)
+		return ok && a == b
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func compare(x, y any) (int, error) {
+	if a, ok := toNumber(x); ok {
+		if b, ok := toNumber(y); ok {
+			switch {
+			case a < b:
+				return -1, nil
+			case a > b:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if a, ok := x.(string); ok {
+		if b, ok := y.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", describe(x), describe(y))
+}
+
+func toNumber(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// describe describes a value in error messages.
+func describe(v any) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%T %v", v, v)
+}
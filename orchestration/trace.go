@@ -0,0 +1,84 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package orchestration
+
+import (
+	"fmt"
+	"time"
+)
+
+// Statuses of runs and steps.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	// StatusSkipped is the status of steps whose condition was false or whose dependencies were
+	// all skipped.
+	StatusSkipped = "skipped"
+	// StatusCanceled is the status of steps that did not finish because the run failed or its
+	// context was done.
+	StatusCanceled = "canceled"
+)
+
+// Trace is the execution trace of a run. It marshals to JSON.
+type Trace struct {
+	Status   string         `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	Input    map[string]any `json:"input"`
+	Started  time.Time      `json:"started"`
+	Finished time.Time      `json:"finished"`
+	// Steps are in the order of the steps of the DAG.
+	Steps []StepTrace `json:"steps"`
+}
+
+// Step returns the trace of a step, and false if the DAG has no such step.
+func (t *Trace) Step(name string) (StepTrace, bool) {
+	for _, step := range t.Steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+	return StepTrace{}, false
+}
+
+// StepTrace is the execution trace of a step.
+type StepTrace struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Started and Finished are zero for steps that did not start.
+	Started  time.Time      `json:"started,omitzero"`
+	Finished time.Time      `json:"finished,omitzero"`
+	Outputs  map[string]any `json:"outputs,omitempty"`
+	// Calls are the app calls of the step, one per item for ForEach steps.
+	Calls []Call `json:"calls,omitempty"`
+}
+
+// Call is an app call of a step.
+type Call struct {
+	Inputs   map[string]any `json:"inputs"`
+	Outputs  map[string]any `json:"outputs,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Attempts []Attempt      `json:"attempts"`
+}
+
+// Attempt is an attempt of an app call.
+type Attempt struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// StepError is returned by Run when a step failed.
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %s: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,9 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package queue is a durable queue of workflow runs. Requests are appended to a log file before
+// Enqueue returns and executed by Run while dify is reachable, so work is accepted even when dify
+// is down and survives process restarts. Runs that keep failing are moved to a dead letter list.
+//
+// Delivery is at least once: a run that completed just before a crash is executed again.
+package queue
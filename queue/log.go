@@ -0,0 +1,146 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// logPerm is the permission of new log files.
+const logPerm = 0o600
+
+// Operations recorded in the log.
+const (
+	opEnqueue = "enqueue"
+	opFail    = "fail"
+	opDone    = "done"
+	opDead    = "dead"
+	opRequeue = "requeue"
+)
+
+// record is a line of the log.
+type record struct {
+	Op       string                     `json:"op"`
+	ID       string                     `json:"id"`
+	Time     time.Time                  `json:"time"`
+	Request  *schema.RunWorkflowRequest `json:"request,omitempty"`
+	Attempts int                        `json:"attempts,omitempty"`
+	Error    string                     `json:"error,omitempty"`
+}
+
+// state is the content of the queue rebuilt from the log.
+type state struct {
+	pending []*Job
+	dead    []*Job
+}
+
+// records returns the number of records compact writes for s.
+func (s state) records() int {
+	return len(s.pending) + 2*len(s.dead)
+}
+
+// replay reads the log file and rebuilds the queue. A last line without a trailing newline was cut
+// short by a crash and is ignored.
+func replay(name string) (state, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state{}, nil
+	}
+	if err != nil {
+		return state{}, err
+	}
+
+	jobs := make(map[string]*Job)
+	var order []string
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines[:len(lines)-1] {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return state{}, fmt.Errorf("%s:%d: %w", name, i+1, err)
+		}
+		job := jobs[r.ID]
+		switch {
+		case r.Op == opEnqueue && r.Request != nil:
+			jobs[r.ID] = &Job{ID: r.ID, Request: *r.Request, EnqueuedAt: r.Time, Attempts: r.Attempts, LastError: r.Error}
+			order = append(order, r.ID)
+		case job == nil:
+			// The job was already removed; the record is stale.
+		case r.Op == opFail:
+			job.Attempts, job.LastError = r.Attempts, r.Error
+		case r.Op == opDead:
+			job.Attempts, job.LastError, job.dead = r.Attempts, r.Error, true
+		case r.Op == opRequeue:
+			job.Attempts, job.LastError, job.dead = 0, "", false
+		case r.Op == opDone:
+			delete(jobs, r.ID)
+		}
+	}
+
+	var s state
+	for _, id := range order {
+		job, ok := jobs[id]
+		if !ok {
+			continue
+		}
+		delete(jobs, id)
+		if job.dead {
+			s.dead = append(s.dead, job)
+		} else {
+			s.pending = append(s.pending, job)
+		}
+	}
+	return s, nil
+}
+
+// compact rewrites the log with only the records needed to rebuild s, replacing the file atomically,
+// and returns the new log opened for appending.
+func compact(name string, s state) (*os.File, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, jobs := range [][]*Job{s.pending, s.dead} {
+		for _, job := range jobs {
+			r := record{Op: opEnqueue, ID: job.ID, Time: job.EnqueuedAt, Request: &job.Request,
+				Attempts: job.Attempts, Error: job.LastError}
+			if err := enc.Encode(r); err != nil {
+				return nil, err
+			}
+			if job.dead {
+				if err := enc.Encode(record{Op: opDead, ID: job.ID, Attempts: job.Attempts, Error: job.LastError}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// The new log is opened before it replaces the old one, so that a failure leaves the old one in use.
+	tmp := name + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, logPerm)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
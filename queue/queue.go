@@ -0,0 +1,422 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+const (
+	// defaultWorkers is the number of concurrent runs when Options.Workers is unset.
+	defaultWorkers = 1
+	// defaultMaxAttempts is the number of failed runs before a job is dead-lettered when
+	// Options.MaxAttempts is unset.
+	defaultMaxAttempts = 5
+	// defaultMinBackoff is the first retry delay when Options.MinBackoff is unset.
+	defaultMinBackoff = time.Second
+	// defaultMaxBackoff bounds the retry delay when Options.MaxBackoff is unset.
+	defaultMaxBackoff = 5 * time.Minute
+	// compactThreshold is the number of obsolete log records after which the log is compacted.
+	compactThreshold = 1000
+)
+
+// ErrClosed is returned when using a closed queue.
+var ErrClosed = errors.New("queue closed")
+
+// Runner executes workflows. It is implemented by *v1.WorkflowService.
+type Runner interface {
+	Run(ctx context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error)
+}
+
+var _ Runner = (*v1.WorkflowService)(nil)
+
+// Job is a queued workflow run.
+type Job struct {
+	ID         string
+	Request    schema.RunWorkflowRequest
+	EnqueuedAt time.Time
+	// Attempts counts the failed runs.
+	Attempts  int
+	LastError string
+
+	dead      bool
+	running   bool
+	notBefore time.Time
+}
+
+// Options configures a queue.
+type Options struct {
+	// Workers is the number of concurrent runs. Default: 1
+	Workers int
+	// MaxAttempts is the number of failed runs after which a job is dead-lettered. Requests rejected
+	// by dify with a client error, or failing for any other reason such as invalid inputs, are
+	// dead-lettered at once. Network errors, including client timeouts, HTTP 429 and server errors
+	// may mean dify is unreachable: they also pause the queue, and count as failed runs so that a
+	// job that always fails this way is eventually dead-lettered.
+	// Default: 5
+	MaxAttempts int
+	// MinBackoff is the first delay after a failure; it doubles on every failure up to MaxBackoff.
+	// Default: 1 second and 5 minutes
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnResult is called after a run succeeded and the job was removed from the queue.
+	OnResult func(job Job, resp schema.RunWorkflowResponse)
+	// OnDead is called after a job was dead-lettered.
+	OnDead func(job Job, err error)
+}
+
+// Queue is a durable queue of workflow runs backed by an append-only log file.
+type Queue struct {
+	runner Runner
+	opts   Options
+	name   string
+
+	mu   sync.Mutex
+	file *os.File
+	// records is the number of records in the log.
+	records    int
+	pending    []*Job
+	dead       []*Job
+	wake       chan struct{}
+	pauseUntil time.Time
+	pause      time.Duration
+}
+
+// Open opens the queue stored in the log file name, creating it if needed. The log is replayed and
+// compacted, so the jobs left by a previous process are run again. The log is compacted again
+// whenever enough records are obsolete.
+func Open(name string, runner Runner, opts Options) (*Queue, error) {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWorkers
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = max(defaultMaxBackoff, opts.MinBackoff)
+	}
+
+	s, err := replay(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := compact(name, s)
+	if err != nil {
+		return nil, err
+	}
+	return &Queue{
+		runner:  runner,
+		opts:    opts,
+		name:    name,
+		file:    file,
+		records: s.records(),
+		pending: s.pending,
+		dead:    s.dead,
+		wake:    make(chan struct{}),
+	}, nil
+}
+
+// Enqueue adds a run to the queue and returns its job ID once it is written to disk.
+// The response mode is always blocking.
+func (q *Queue) Enqueue(req schema.RunWorkflowRequest) (string, error) {
+	req.ResponseMode = v1.BlockingMode
+	job := &Job{ID: newID(), Request: req, EnqueuedAt: time.Now()}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.append(record{Op: opEnqueue, ID: job.ID, Time: job.EnqueuedAt, Request: &job.Request}); err != nil {
+		return "", err
+	}
+	q.pending = append(q.pending, job)
+	q.signal()
+	return job.ID, nil
+}
+
+// Pending returns a copy of the jobs waiting to be run, including the running ones.
+func (q *Queue) Pending() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return copyJobs(q.pending)
+}
+
+// Dead returns a copy of the dead-lettered jobs.
+func (q *Queue) Dead() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return copyJobs(q.dead)
+}
+
+// Requeue moves a dead-lettered job back to the queue with its attempts reset.
+func (q *Queue) Requeue(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := slices.IndexFunc(q.dead, func(job *Job) bool { return job.ID == id })
+	if i < 0 {
+		return errors.New("no dead job " + id)
+	}
+	if err := q.append(record{Op: opRequeue, ID: id, Time: time.Now()}); err != nil {
+		return err
+	}
+	job := q.dead[i]
+	q.dead = slices.Delete(q.dead, i, i+1)
+	job.dead, job.Attempts, job.LastError, job.notBefore = false, 0, "", time.Time{}
+	q.pending = append(q.pending, job)
+	q.signal()
+	return nil
+}
+
+// Discard removes a dead-lettered job for good.
+func (q *Queue) Discard(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := slices.IndexFunc(q.dead, func(job *Job) bool { return job.ID == id })
+	if i < 0 {
+		return errors.New("no dead job " + id)
+	}
+	if err := q.append(record{Op: opDone, ID: id, Time: time.Now()}); err != nil {
+		return err
+	}
+	q.dead = slices.Delete(q.dead, i, i+1)
+	q.compact()
+	return nil
+}
+
+// Run executes the queued jobs until ctx is done. Jobs interrupted by ctx stay in the queue.
+func (q *Queue) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for range q.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				job, ok := q.next(ctx)
+				if !ok {
+					return
+				}
+				q.execute(ctx, job)
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// Close closes the log file. Run must have returned.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.file == nil {
+		return ErrClosed
+	}
+	err := q.file.Close()
+	q.file = nil
+	return err
+}
+
+// next waits for a job that is ready to run and marks it running.
+func (q *Queue) next(ctx context.Context) (*Job, bool) {
+	for {
+		q.mu.Lock()
+		now := time.Now()
+		var wakeAt time.Time
+		var ready *Job
+		if now.Before(q.pauseUntil) {
+			wakeAt = q.pauseUntil
+		} else {
+			for _, job := range q.pending {
+				if job.running {
+					continue
+				}
+				if !now.Before(job.notBefore) {
+					ready = job
+					break
+				}
+				if wakeAt.IsZero() || job.notBefore.Before(wakeAt) {
+					wakeAt = job.notBefore
+				}
+			}
+		}
+		if ready != nil {
+			ready.running = true
+			q.mu.Unlock()
+			return ready, true
+		}
+		wake := q.wake
+		q.mu.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if !wakeAt.IsZero() {
+			timer = time.NewTimer(time.Until(wakeAt))
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-wake:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return nil, false
+		}
+	}
+}
+
+// execute runs a job and records its outcome.
+func (q *Queue) execute(ctx context.Context, job *Job) {
+	resp, err := q.runner.Run(ctx, job.Request)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	defer q.signal()
+	job.running = false
+	// A client timeout also matches context.DeadlineExceeded, so ctx tells whether the run was
+	// interrupted.
+	if ctx.Err() != nil {
+		return
+	}
+
+	switch {
+	case err == nil && resp.Data.Status == schema.WorkflowStatusSucceeded:
+		q.pause = 0
+		if q.append(record{Op: opDone, ID: job.ID, Time: time.Now()}) != nil {
+			// The job stays queued and runs again once the log is writable.
+			return
+		}
+		q.remove(job)
+		q.compact()
+		if q.opts.OnResult != nil {
+			q.callback(func() { q.opts.OnResult(*job, resp) })
+		}
+	case err != nil && v1.IsTransient(err):
+		q.pause = q.backoff(q.pause)
+		q.pauseUntil = time.Now().Add(q.pause)
+		q.fail(job, err, true)
+	default:
+		q.pause = 0
+		var runErr *v1.WorkflowRunError
+		if err == nil {
+			err = &v1.WorkflowRunError{RunID: resp.WorkflowRunID, Status: resp.Data.Status, Message: resp.Data.Error}
+		}
+		q.fail(job, err, errors.As(err, &runErr))
+	}
+}
+
+// fail records a failed run, dead-lettering the job when the run is not retryable or the job ran
+// out of attempts. The job is delayed even if the log cannot be written, so that it is not retried
+// at once.
+func (q *Queue) fail(job *Job, err error, retryable bool) {
+	attempts := job.Attempts + 1
+	dead := !retryable || attempts >= q.opts.MaxAttempts
+
+	op := opFail
+	if dead {
+		op = opDead
+	}
+	appendErr := q.append(record{Op: op, ID: job.ID, Time: time.Now(), Attempts: attempts, Error: err.Error()})
+	job.Attempts, job.LastError = attempts, err.Error()
+	if !dead || appendErr != nil {
+		delay := q.opts.MinBackoff
+		for range attempts - 1 {
+			delay = q.backoff(delay)
+		}
+		job.notBefore = time.Now().Add(delay)
+		return
+	}
+	q.remove(job)
+	job.dead = true
+	q.dead = append(q.dead, job)
+	q.compact()
+	if q.opts.OnDead != nil {
+		q.callback(func() { q.opts.OnDead(*job, err) })
+	}
+}
+
+// callback runs fn without holding the lock, so that it may use the queue.
+func (q *Queue) callback(fn func()) {
+	q.mu.Unlock()
+	defer q.mu.Lock()
+	fn()
+}
+
+func (q *Queue) backoff(d time.Duration) time.Duration {
+	if d <= 0 {
+		return q.opts.MinBackoff
+	}
+	return min(d*2, q.opts.MaxBackoff)
+}
+
+func (q *Queue) remove(job *Job) {
+	q.pending = slices.DeleteFunc(q.pending, func(j *Job) bool { return j == job })
+}
+
+// append writes a record to the log and syncs it to disk.
+func (q *Queue) append(r record) error {
+	if q.file == nil {
+		return ErrClosed
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := q.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := q.file.Sync(); err != nil {
+		return err
+	}
+	q.records++
+	return nil
+}
+
+// compact compacts the log once enough of its records are obsolete. On failure the current log is
+// kept, and compaction is tried again after the next record.
+func (q *Queue) compact() {
+	s := state{pending: q.pending, dead: q.dead}
+	if q.file == nil || q.records-s.records() < compactThreshold {
+		return
+	}
+	file, err := compact(q.name, s)
+	if err != nil {
+		return
+	}
+	q.file.Close()
+	q.file, q.records = file, s.records()
+}
+
+// signal wakes the workers waiting for a job.
+func (q *Queue) signal() {
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
+
+func copyJobs(jobs []*Job) []Job {
+	out := make([]Job, 0, len(jobs))
+	for _, job := range jobs {
+		out = append(out, *job)
+	}
+	return out
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
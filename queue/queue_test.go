@@ -0,0 +1,294 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+type fakeRunner struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeRunner) Run(_ context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error) {
+	var inputs map[string]string
+	if err := json.Unmarshal(req.Inputs, &inputs); err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
+	f.mu.Lock()
+	f.calls[inputs["kind"]]++
+	calls := f.calls[inputs["kind"]]
+	f.mu.Unlock()
+
+	switch inputs["kind"] {
+	case "down":
+		if calls == 1 {
+			return schema.RunWorkflowResponse{}, &v1.APIError{StatusCode: http.StatusBadGateway}
+		}
+	case "invalid":
+		return schema.RunWorkflowResponse{}, &v1.APIError{StatusCode: http.StatusBadRequest, Code: "invalid_param"}
+	case "malformed":
+		return schema.RunWorkflowResponse{}, &v1.ValidationError{Fields: []v1.FieldError{{Variable: "kind", Message: "is malformed"}}}
+	case "failing":
+		return schema.RunWorkflowResponse{Data: schema.RunWorkflowResponseData{Status: schema.WorkflowStatusFailed, Error: "boom"}}, nil
+	}
+	return schema.RunWorkflowResponse{Data: schema.RunWorkflowResponseData{Status: schema.WorkflowStatusSucceeded}}, nil
+}
+
+func request(kind string) schema.RunWorkflowRequest {
+	return schema.RunWorkflowRequest{Inputs: json.RawMessage(`{"kind":"` + kind + `"}`), User: "abc"}
+}
+
+func TestQueue(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "queue.log")
+	runner := &fakeRunner{calls: map[string]int{}}
+
+	// Jobs enqueued while nothing runs survive a restart.
+	q, err := Open(name, runner, Options{})
+	require.NoError(t, err)
+	for _, kind := range []string{"ok", "down", "invalid", "failing"} {
+		_, err = q.Enqueue(request(kind))
+		require.NoError(t, err)
+	}
+	require.NoError(t, q.Close())
+
+	// A torn last line left by a crash is ignored.
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op": "done", "id"`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	var mu sync.Mutex
+	var succeeded, dead []string
+	finished := make(chan struct{}, 4)
+	opts := Options{
+		Workers:     2,
+		MaxAttempts: 2,
+		MinBackoff:  time.Millisecond,
+		OnResult: func(job Job, _ schema.RunWorkflowResponse) {
+			mu.Lock()
+			defer mu.Unlock()
+			succeeded = append(succeeded, string(job.Request.Inputs))
+			finished <- struct{}{}
+		},
+		OnDead: func(job Job, _ error) {
+			mu.Lock()
+			defer mu.Unlock()
+			dead = append(dead, string(job.Request.Inputs))
+			finished <- struct{}{}
+		},
+	}
+	q, err = Open(name, runner, opts)
+	require.NoError(t, err)
+	require.Len(t, q.Pending(), 4)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() { done <- q.Run(ctx) }()
+	for range 4 {
+		select {
+		case <-finished:
+		case <-time.After(5 * time.Second):
+			t.Fatal("jobs did not finish")
+		}
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	assert.ElementsMatch(t, []string{`{"kind":"ok"}`, `{"kind":"down"}`}, succeeded)
+	assert.ElementsMatch(t, []string{`{"kind":"invalid"}`, `{"kind":"failing"}`}, dead)
+	assert.Equal(t, map[string]int{"ok": 1, "down": 2, "invalid": 1, "failing": 2}, runner.calls)
+	require.NoError(t, q.Close())
+
+	// Dead jobs are kept across restarts and can be requeued.
+	q, err = Open(name, runner, opts)
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Empty(t, q.Pending())
+	deadJobs := q.Dead()
+	require.Len(t, deadJobs, 2)
+	require.NoError(t, q.Requeue(deadJobs[0].ID))
+	pending := q.Pending()
+	require.Len(t, pending, 1)
+	assert.Zero(t, pending[0].Attempts)
+}
+
+func TestQueueDeadLettersInvalidJobs(t *testing.T) {
+	runner := &fakeRunner{calls: map[string]int{}}
+	results := make(chan string, 2)
+	q, err := Open(filepath.Join(t.TempDir(), "queue.log"), runner, Options{
+		Workers:  1,
+		OnResult: func(job Job, _ schema.RunWorkflowResponse) { results <- "done " + string(job.Request.Inputs) },
+		OnDead:   func(job Job, _ error) { results <- "dead " + string(job.Request.Inputs) },
+	})
+	require.NoError(t, err)
+	defer q.Close()
+	// A job that can never be sent must not pause the queue in front of a good one.
+	for _, kind := range []string{"malformed", "ok"} {
+		_, err = q.Enqueue(request(kind))
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() { done <- q.Run(ctx) }()
+	var got []string
+	for range 2 {
+		select {
+		case r := <-results:
+			got = append(got, r)
+		case <-time.After(5 * time.Second):
+			t.Fatal("jobs did not finish")
+		}
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	assert.Equal(t, []string{`dead {"kind":"malformed"}`, `done {"kind":"ok"}`}, got)
+	assert.Equal(t, map[string]int{"malformed": 1, "ok": 1}, runner.calls)
+	assert.Empty(t, q.Pending())
+	assert.Len(t, q.Dead(), 1)
+}
+
+// httpRunner runs workflows against a server with a client timeout, like *v1.WorkflowService.
+type httpRunner struct {
+	client *http.Client
+	url    string
+}
+
+func (r httpRunner) Run(ctx context.Context, _ schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, nil)
+	if err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return schema.RunWorkflowResponse{}, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return schema.RunWorkflowResponse{}, &v1.APIError{StatusCode: resp.StatusCode}
+	}
+	var out schema.RunWorkflowResponse
+	return out, json.NewDecoder(resp.Body).Decode(&out)
+}
+
+func TestQueueRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			// The first run outlasts the client timeout.
+			if calls.Add(1) == 1 {
+				<-r.Context().Done()
+				return
+			}
+			fmt.Fprint(w, `{"data": {"status": "succeeded"}}`)
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{path: "/slow", want: "done after 1 failed runs"},
+		{path: "/down", want: "dead after 3 failed runs"},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			results := make(chan string, 1)
+			q, err := Open(filepath.Join(t.TempDir(), "queue.log"), httpRunner{client: client, url: server.URL + tt.path}, Options{
+				MaxAttempts: 3,
+				MinBackoff:  time.Millisecond,
+				OnResult: func(job Job, _ schema.RunWorkflowResponse) {
+					results <- fmt.Sprintf("done after %d failed runs", job.Attempts)
+				},
+				OnDead: func(job Job, _ error) {
+					results <- fmt.Sprintf("dead after %d failed runs", job.Attempts)
+				},
+			})
+			require.NoError(t, err)
+			defer q.Close()
+			_, err = q.Enqueue(request("ok"))
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			done := make(chan error)
+			go func() { done <- q.Run(ctx) }()
+			var got string
+			select {
+			case got = <-results:
+			case <-time.After(5 * time.Second):
+				t.Fatal("job did not finish")
+			}
+			cancel()
+			require.ErrorIs(t, <-done, context.Canceled)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueueCompactsLog(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "queue.log")
+	finished := make(chan struct{}, compactThreshold)
+	q, err := Open(name, &fakeRunner{calls: map[string]int{}}, Options{
+		Workers:  4,
+		OnResult: func(Job, schema.RunWorkflowResponse) { finished <- struct{}{} },
+	})
+	require.NoError(t, err)
+	defer q.Close()
+	// Every finished job leaves two obsolete records.
+	jobs := compactThreshold/2 + 1
+	for range jobs {
+		_, err = q.Enqueue(request("ok"))
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() { done <- q.Run(ctx) }()
+	for range jobs {
+		select {
+		case <-finished:
+		case <-time.After(10 * time.Second):
+			t.Fatal("jobs did not finish")
+		}
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	// The log was compacted and is still appended to.
+	_, err = q.Enqueue(request("ok"))
+	require.NoError(t, err)
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Less(t, bytes.Count(data, []byte("\n")), jobs)
+	require.NoError(t, q.Close())
+	q, err = Open(name, &fakeRunner{calls: map[string]int{}}, Options{})
+	require.NoError(t, err)
+	assert.Len(t, q.Pending(), 1)
+}
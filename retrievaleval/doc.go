@@ -0,0 +1,7 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retrievaleval measures the retrieval quality of a knowledge base. It runs the queries of a
+// golden set through the retrieval API with one or more retrieval settings and computes recall@k,
+// precision@k, MRR and nDCG@k, so that chunking and retrieval settings can be compared.
+package retrievaleval
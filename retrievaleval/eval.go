@@ -0,0 +1,151 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retrievaleval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/internal/syncutil"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// defaultK is the number of results considered when Options.K is unset.
+const defaultK = 5
+
+// Retriever is the knowledge API used by the evaluation. It is implemented by *v1.DatasetService.
+type Retriever interface {
+	// Get retrieves the dataset, whose retrieval settings are used by configs without their own.
+	Get(ctx context.Context, datasetID string) (schema.Dataset, error)
+	Retrieve(ctx context.Context, datasetID string, req schema.RetrieveRequest) (schema.RetrieveResponse, error)
+}
+
+var _ Retriever = (*v1.DatasetService)(nil)
+
+// Config is a named set of retrieval settings to evaluate.
+type Config struct {
+	Name string `json:"name" yaml:"name"`
+	// RetrievalModel is sent with every query; the dataset settings are used when it is nil.
+	// Its top_k is raised to Options.K, so that the metrics consider k results.
+	RetrievalModel *schema.RetrievalModel `json:"retrieval_model,omitempty" yaml:"retrieval_model,omitempty"`
+}
+
+// Options configures an evaluation.
+type Options struct {
+	// K is the number of results considered by the metrics. Default: 5
+	K int
+	// Concurrency is the maximum number of concurrent queries. Default: 4
+	Concurrency int
+}
+
+// Evaluate runs every case with every config and scores the results. Queries that fail are recorded
+// in the report and left out of the means; the returned error joins their errors. An error getting
+// the dataset retrieval settings fails the evaluation.
+func Evaluate(
+	ctx context.Context,
+	retriever Retriever,
+	datasetID string,
+	cases []Case,
+	configs []Config,
+	opts Options,
+) (*Report, error) {
+	if opts.K <= 0 {
+		opts.K = defaultK
+	}
+	if len(configs) == 0 {
+		configs = []Config{{Name: "default"}}
+	}
+	models, err := retrievalModels(ctx, retriever, datasetID, configs, opts.K)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{K: opts.K, Configs: make([]ConfigResult, len(configs))}
+	for i, config := range configs {
+		result := &report.Configs[i]
+		result.Name = config.Name
+		result.Queries = make([]QueryResult, len(cases))
+		for j, c := range cases {
+			result.Queries[j].CaseID, result.Queries[j].Query = c.ID, c.Query
+		}
+	}
+	queryErrs := syncutil.Each(ctx, len(configs)*len(cases), opts.Concurrency, func(ctx context.Context, n int) error {
+		i, j := n/len(cases), n%len(cases)
+		return run(ctx, retriever, datasetID, cases[j], models[i], opts.K, &report.Configs[i].Queries[j])
+	})
+	for n, err := range queryErrs {
+		if err != nil {
+			report.Configs[n/len(cases)].Queries[n%len(cases)].Err = err.Error()
+		}
+	}
+
+	var errs []error
+	for i := range report.Configs {
+		result := &report.Configs[i]
+		metrics := make([]Metrics, 0, len(result.Queries))
+		for _, q := range result.Queries {
+			if q.Err != "" {
+				result.Failed++
+				errs = append(errs, errors.New(result.Name+" "+q.CaseID+": "+q.Err))
+				continue
+			}
+			metrics = append(metrics, q.Metrics)
+		}
+		result.Mean = mean(metrics)
+	}
+	return report, errors.Join(errs...)
+}
+
+// retrievalModels returns the retrieval settings of every config with a top_k of at least k. Configs
+// without settings use those of the dataset, which are only retrieved when needed.
+func retrievalModels(
+	ctx context.Context,
+	retriever Retriever,
+	datasetID string,
+	configs []Config,
+	k int,
+) ([]*schema.RetrievalModel, error) {
+	var datasetModel *schema.RetrievalModel
+	models := make([]*schema.RetrievalModel, len(configs))
+	for i, config := range configs {
+		model := config.RetrievalModel
+		if model == nil {
+			if datasetModel == nil {
+				dataset, err := retriever.Get(ctx, datasetID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get the dataset retrieval settings: %w", err)
+				}
+				datasetModel = &dataset.RetrievalModel
+			}
+			model = datasetModel
+		}
+		m := *model
+		m.TopK = max(m.TopK, k)
+		models[i] = &m
+	}
+	return models, nil
+}
+
+// run retrieves the results of a case and scores them into q.
+func run(ctx context.Context, retriever Retriever, datasetID string, c Case, model *schema.RetrievalModel, k int, q *QueryResult) error {
+	resp, err := retriever.Retrieve(ctx, datasetID, schema.RetrieveRequest{Query: c.Query, RetrievalModel: model})
+	if err != nil {
+		return err
+	}
+	metrics, relevant, missing := Score(c, resp.Records, k)
+	q.Metrics, q.Missing = metrics, missing
+	for i, r := range resp.Records[:len(relevant)] {
+		q.Results = append(q.Results, Result{
+			Rank:       i + 1,
+			DocumentID: r.Segment.Document.ID,
+			Document:   r.Segment.Document.Name,
+			SegmentID:  r.Segment.ID,
+			Score:      r.Score,
+			Relevant:   relevant[i],
+		})
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retrievaleval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+type fakeRetriever map[string][]schema.RetrievalRecord
+
+func (f fakeRetriever) Get(context.Context, string) (schema.Dataset, error) {
+	return schema.Dataset{RetrievalModel: schema.RetrievalModel{SearchMethod: schema.SearchMethodSemantic, TopK: 1}}, nil
+}
+
+func (f fakeRetriever) Retrieve(_ context.Context, _ string, req schema.RetrieveRequest) (schema.RetrieveResponse, error) {
+	records, ok := f[req.Query]
+	if !ok {
+		return schema.RetrieveResponse{}, errors.New("unknown query")
+	}
+	return schema.RetrieveResponse{Records: records[:min(req.RetrievalModel.TopK, len(records))]}, nil
+}
+
+func record(documentID, segmentID, content string) schema.RetrievalRecord {
+	var r schema.RetrievalRecord
+	r.Segment.ID, r.Segment.Content, r.Segment.Document.ID = segmentID, content, documentID
+	return r
+}
+
+func TestScore(t *testing.T) {
+	c := Case{Documents: []string{"d1", "d2"}}
+	records := []schema.RetrievalRecord{
+		record("d3", "s1", ""),
+		record("d1", "s2", ""),
+		record("d4", "s3", ""),
+	}
+	m, relevant, missing := Score(c, records, 3)
+	assert.Equal(t, []bool{false, true, false}, relevant)
+	assert.Equal(t, []string{"document:d2"}, missing)
+	assert.InDelta(t, 0.5, m.Recall, 1e-9)
+	assert.InDelta(t, 1.0/3, m.Precision, 1e-9)
+	assert.InDelta(t, 0.5, m.MRR, 1e-9)
+	assert.InDelta(t, (1/math.Log2(3))/(1+1/math.Log2(3)), m.NDCG, 1e-9)
+
+	c = Case{Documents: []string{"d1"}}
+	records = []schema.RetrievalRecord{record("d1", "s1", ""), record("d1", "s2", "")}
+	m, relevant, _ = Score(c, records, 2)
+	assert.Equal(t, []bool{true, true}, relevant)
+	assert.LessOrEqual(t, m.NDCG, 1.0)
+	assert.InDelta(t, 1.0, m.NDCG, 1e-9)
+}
+
+func TestEvaluate(t *testing.T) {
+	golden := `
+- id: install
+  query: how to install
+  texts: ["go get"]
+- query: pricing
+  segments: [s9]
+- query: broken
+  documents: [d1]
+`
+	cases, err := Read(strings.NewReader(golden), FormatYAML)
+	require.NoError(t, err)
+	require.Len(t, cases, 3)
+	assert.Equal(t, "2", cases[1].ID)
+
+	retriever := fakeRetriever{
+		"how to install": {record("d1", "s1", "Run GO GET to install.")},
+		"pricing":        {record("d2", "s2", ""), record("d2", "s3", "")},
+	}
+	report, err := Evaluate(t.Context(), retriever, "ds", cases, nil, Options{K: 2})
+	require.Error(t, err)
+	require.Len(t, report.Configs, 1)
+	result := report.Configs[0]
+	assert.Equal(t, 1, result.Failed)
+	assert.InDelta(t, 0.5, result.Mean.Recall, 1e-9)
+	assert.InDelta(t, 0.5, result.Mean.MRR, 1e-9)
+
+	var md bytes.Buffer
+	require.NoError(t, report.WriteMarkdown(&md))
+	assert.Contains(t, md.String(), "| install | how to install | 1.000 |")
+}
+
+func TestEvaluateRaisesTopK(t *testing.T) {
+	retriever := fakeRetriever{"pricing": {record("d1", "s1", ""), record("d2", "s2", "")}}
+	configs := []Config{
+		{Name: "dataset"},
+		{Name: "low", RetrievalModel: &schema.RetrievalModel{SearchMethod: schema.SearchMethodKeyword, TopK: 1}},
+	}
+	report, err := Evaluate(t.Context(), retriever, "ds", []Case{{ID: "1", Query: "pricing", Documents: []string{"d2"}}}, configs, Options{K: 2})
+	require.NoError(t, err)
+	for _, result := range report.Configs {
+		assert.InDelta(t, 1.0, result.Mean.Recall, 1e-9, result.Name)
+		assert.Len(t, result.Queries[0].Results, 2, result.Name)
+	}
+	assert.Equal(t, 1, configs[1].RetrievalModel.TopK)
+}
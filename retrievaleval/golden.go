@@ -0,0 +1,118 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retrievaleval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a file format for golden sets.
+type Format string
+
+const (
+	// FormatJSONL is a file with one case object per line.
+	FormatJSONL Format = "jsonl"
+	// FormatYAML is a YAML list of cases.
+	FormatYAML Format = "yaml"
+)
+
+// maxLineSize is the longest JSONL line accepted.
+const maxLineSize = 1 << 20
+
+// Case is a query of a golden set with the results expected for it. A retrieved segment is relevant
+// when its ID is in Segments, its document ID is in Documents, or its content contains one of Texts.
+type Case struct {
+	// ID identifies the case in reports. Default: the position of the case in the set
+	ID        string   `json:"id" yaml:"id"`
+	Query     string   `json:"query" yaml:"query"`
+	Documents []string `json:"documents,omitempty" yaml:"documents,omitempty"`
+	Segments  []string `json:"segments,omitempty" yaml:"segments,omitempty"`
+	Texts     []string `json:"texts,omitempty" yaml:"texts,omitempty"`
+}
+
+// expected returns the number of expected items.
+func (c Case) expected() int {
+	return len(c.Documents) + len(c.Segments) + len(c.Texts)
+}
+
+// FormatFromPath returns the format matching the extension of name.
+func FormatFromPath(name string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("unsupported golden set %q, expected .jsonl or .yaml", name)
+	}
+}
+
+// LoadFile reads a golden set, choosing the format from the file extension.
+func LoadFile(name string) ([]Case, error) {
+	format, err := FormatFromPath(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f, format)
+}
+
+// Read reads a golden set in the given format. Every case needs a query and at least one expected
+// document, segment or text.
+func Read(r io.Reader, format Format) ([]Case, error) {
+	var cases []Case
+	switch format {
+	case FormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxLineSize)
+		for line := 1; scanner.Scan(); line++ {
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			var c Case
+			if err := json.Unmarshal(data, &c); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			cases = append(cases, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		if err := yaml.NewDecoder(r).Decode(&cases); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	for i := range cases {
+		if cases[i].ID == "" {
+			cases[i].ID = strconv.Itoa(i + 1)
+		}
+		if strings.TrimSpace(cases[i].Query) == "" {
+			return nil, fmt.Errorf("case %s: query is required", cases[i].ID)
+		}
+		if cases[i].expected() == 0 {
+			return nil, fmt.Errorf("case %s: no expected documents, segments or texts", cases[i].ID)
+		}
+	}
+	return cases, nil
+}
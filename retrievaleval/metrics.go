@@ -0,0 +1,122 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retrievaleval
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// Metrics holds the retrieval quality of a query, or the mean over the queries of a config.
+type Metrics struct {
+	// Recall is the share of expected items found in the first k results.
+	Recall float64 `json:"recall"`
+	// Precision is the share of the first k results that are relevant.
+	Precision float64 `json:"precision"`
+	// MRR is the reciprocal rank of the first relevant result, 0 when there is none.
+	MRR float64 `json:"mrr"`
+	// NDCG is the normalized discounted cumulative gain of the first k results, with binary relevance.
+	// Only the first record matching an expected item gains.
+	NDCG float64 `json:"ndcg"`
+}
+
+// Score computes the metrics of the records retrieved for c, considering the first k records.
+// It also returns the relevance of each considered record and the expected items that were not found.
+func Score(c Case, records []schema.RetrievalRecord, k int) (Metrics, []bool, []string) {
+	records = records[:min(k, len(records))]
+	relevant := make([]bool, len(records))
+	found := make(map[string]bool)
+	var m Metrics
+	var hits int
+	var dcg float64
+	for i, r := range records {
+		// Records repeating found items, such as more segments of a document, gain nothing,
+		// which keeps nDCG at most 1.
+		var gain bool
+		for _, item := range matches(c, r) {
+			relevant[i] = true
+			gain = gain || !found[item]
+			found[item] = true
+		}
+		if gain {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+		if relevant[i] {
+			hits++
+			if m.MRR == 0 {
+				m.MRR = 1 / float64(i+1)
+			}
+		}
+	}
+
+	var missing []string
+	for _, item := range expectedItems(c) {
+		if !found[item] {
+			missing = append(missing, item)
+		}
+	}
+	expected := c.expected()
+	m.Recall = float64(expected-len(missing)) / float64(expected)
+	if k > 0 {
+		m.Precision = float64(hits) / float64(k)
+	}
+	var idcg float64
+	for i := range min(expected, k) {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	if idcg > 0 {
+		m.NDCG = dcg / idcg
+	}
+	return m, relevant, missing
+}
+
+// matches returns the expected items matched by a record.
+func matches(c Case, r schema.RetrievalRecord) []string {
+	var items []string
+	if slices.Contains(c.Documents, r.Segment.Document.ID) {
+		items = append(items, "document:"+r.Segment.Document.ID)
+	}
+	if slices.Contains(c.Segments, r.Segment.ID) {
+		items = append(items, "segment:"+r.Segment.ID)
+	}
+	content := strings.ToLower(r.Segment.Content)
+	for _, text := range c.Texts {
+		if strings.Contains(content, strings.ToLower(text)) {
+			items = append(items, "text:"+text)
+		}
+	}
+	return items
+}
+
+func expectedItems(c Case) []string {
+	items := make([]string, 0, c.expected())
+	for _, id := range c.Documents {
+		items = append(items, "document:"+id)
+	}
+	for _, id := range c.Segments {
+		items = append(items, "segment:"+id)
+	}
+	for _, text := range c.Texts {
+		items = append(items, "text:"+text)
+	}
+	return items
+}
+
+func mean(metrics []Metrics) Metrics {
+	var m Metrics
+	if len(metrics) == 0 {
+		return m
+	}
+	for _, q := range metrics {
+		m.Recall += q.Recall
+		m.Precision += q.Precision
+		m.MRR += q.MRR
+		m.NDCG += q.NDCG
+	}
+	n := float64(len(metrics))
+	return Metrics{Recall: m.Recall / n, Precision: m.Precision / n, MRR: m.MRR / n, NDCG: m.NDCG / n}
+}
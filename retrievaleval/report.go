@@ -0,0 +1,88 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package retrievaleval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report holds the results of an evaluation.
+type Report struct {
+	K       int            `json:"k"`
+	Configs []ConfigResult `json:"configs"`
+}
+
+// ConfigResult holds the results of a config.
+type ConfigResult struct {
+	Name string `json:"name"`
+	// Mean is the mean of the metrics of the queries that did not fail.
+	Mean    Metrics       `json:"mean"`
+	Failed  int           `json:"failed"`
+	Queries []QueryResult `json:"queries"`
+}
+
+// QueryResult holds the results of a case with a config.
+type QueryResult struct {
+	CaseID  string   `json:"case_id"`
+	Query   string   `json:"query"`
+	Metrics Metrics  `json:"metrics"`
+	Results []Result `json:"results"`
+	// Missing lists the expected items that were not retrieved, such as "document:<id>".
+	Missing []string `json:"missing,omitempty"`
+	Err     string   `json:"error,omitempty"`
+}
+
+// Result is a retrieved segment among the first k.
+type Result struct {
+	Rank       int     `json:"rank"`
+	DocumentID string  `json:"document_id"`
+	Document   string  `json:"document"`
+	SegmentID  string  `json:"segment_id"`
+	Score      float64 `json:"score"`
+	Relevant   bool    `json:"relevant"`
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes a summary table comparing the configs followed by a table of the queries
+// of each config.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Retrieval evaluation (k = %d)\n\n", r.K)
+	fmt.Fprintf(&b, "| Config | Recall@%d | Precision@%d | MRR | nDCG@%d | Failed |\n", r.K, r.K, r.K)
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, c := range r.Configs {
+		fmt.Fprintf(&b, "| %s | %.3f | %.3f | %.3f | %.3f | %d |\n",
+			escapeCell(c.Name), c.Mean.Recall, c.Mean.Precision, c.Mean.MRR, c.Mean.NDCG, c.Failed)
+	}
+	for _, c := range r.Configs {
+		fmt.Fprintf(&b, "\n## %s\n\n", c.Name)
+		b.WriteString("| Case | Query | Recall | Precision | MRR | nDCG | Missing |\n")
+		b.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, q := range c.Queries {
+			if q.Err != "" {
+				fmt.Fprintf(&b, "| %s | %s | error: %s | | | | |\n", escapeCell(q.CaseID), escapeCell(q.Query), escapeCell(q.Err))
+				continue
+			}
+			fmt.Fprintf(&b, "| %s | %s | %.3f | %.3f | %.3f | %.3f | %s |\n", escapeCell(q.CaseID), escapeCell(q.Query),
+				q.Metrics.Recall, q.Metrics.Precision, q.Metrics.MRR, q.Metrics.NDCG, escapeCell(strings.Join(q.Missing, ", ")))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeCell makes s safe to use in a markdown table cell.
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
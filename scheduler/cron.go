@@ -0,0 +1,184 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchYears bounds the search of the next activation of a schedule, so that schedules which
+// never fire, such as February 30, do not loop forever.
+const maxSearchYears = 5
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var fields = [5]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	// 7 is accepted for Sunday and folded to 0.
+	{name: "day of week", min: 0, max: 7, names: dayNames},
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	expr                   string
+	minute, hour, dom, dow uint64
+	month                  uint64
+	// domStar and dowStar record a day field starting with an asterisk. When both day fields are
+	// restricted, a day matches either of them, like in cron.
+	domStar, dowStar bool
+}
+
+// ParseCron parses a standard five field cron expression: minute, hour, day of month, month and
+// day of week. Fields accept *, values, ranges (1-5), lists (1,15) and steps (*/15, 0-30/10), and
+// months and days of the week accept three letter names. The @yearly, @monthly, @weekly, @daily
+// and @hourly macros are also accepted.
+func ParseCron(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(parts))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return &Schedule{
+		expr:    expr,
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first activation of the schedule strictly after t, in the location of t.
+// It returns the zero time if the schedule never fires within the next five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxSearchYears
+	for t.Year() <= limit {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// parseField parses a comma separated list of a cron field into a bit set.
+func parseField(spec string, f field) (uint64, error) {
+	var bits uint64
+	for item := range strings.SplitSeq(spec, ",") {
+		rangeSpec, stepSpec, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepSpec)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepSpec, f.name)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		if rangeSpec != "*" {
+			loSpec, hiSpec, isRange := strings.Cut(rangeSpec, "-")
+			var err error
+			if lo, err = parseValue(loSpec, f); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = parseValue(hiSpec, f); err != nil {
+					return 0, err
+				}
+			case !hasStep:
+				// A single value; with a step, 5/15 means 5-max/15.
+				hi = lo
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangeSpec, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(spec string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(spec)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(spec)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, expected %d-%d", spec, f.name, f.min, f.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,7 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package scheduler runs workflows on cron schedules inside a Go service. Inputs are templates
+// of the activation time, the runs of a job never overlap, and the last run of every job is
+// recorded, optionally in a Store so that runs missed during a restart can be caught up.
+package scheduler
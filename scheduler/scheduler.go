@@ -0,0 +1,361 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"text/template"
+	"time"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+const (
+	// StatusError is the status of runs that could not be executed.
+	StatusError = "error"
+
+	// defaultUser is the end user of the runs when Job.User is unset.
+	defaultUser = "scheduler"
+	// maxCatchUp is the number of missed activations CatchUpAll runs at most, the latest ones.
+	maxCatchUp = 100
+)
+
+// Runner executes workflows. It is implemented by *v1.WorkflowService.
+type Runner interface {
+	Run(ctx context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error)
+}
+
+var _ Runner = (*v1.WorkflowService)(nil)
+
+// CatchUp is the policy for activations missed while the scheduler was stopped or while the
+// previous run of the job was still running.
+type CatchUp int
+
+const (
+	// CatchUpSkip drops the missed activations.
+	CatchUpSkip CatchUp = iota
+	// CatchUpLatest runs the job once, for the latest missed activation.
+	CatchUpLatest
+	// CatchUpAll runs the job for every missed activation, oldest first, up to the latest 100.
+	CatchUpAll
+)
+
+// Job is a workflow run on a schedule.
+type Job struct {
+	// Name identifies the job in records and in the store.
+	Name string
+	// Schedule is a cron expression, see ParseCron.
+	Schedule string
+	// Workflow executes the runs, usually the *v1.WorkflowService of the app.
+	Workflow Runner
+	// Inputs are the inputs of the runs. String values are text/template templates executed with
+	// a TemplateData, so that {{.Time.Format "2006-01-02"}} is the date of the activation.
+	Inputs map[string]any
+	// User is the end user of the runs. Default: scheduler
+	User  string
+	Files []schema.RunWorkflowRequestFile
+	// Jitter delays every run by a random duration up to Jitter, to spread the runs of jobs
+	// sharing a schedule.
+	Jitter time.Duration
+	// Timeout bounds every run. Zero is unlimited.
+	Timeout time.Duration
+	// CatchUp is the policy for missed activations. Default: CatchUpSkip
+	CatchUp CatchUp
+}
+
+// TemplateData is the data of the input templates.
+type TemplateData struct {
+	Job string
+	// Time is the activation of the run, before jitter.
+	Time time.Time
+	// Prev is the activation of the previous run of the job, zero for the first run.
+	Prev time.Time
+}
+
+// Record is the outcome of a run.
+type Record struct {
+	Job       string          `json:"job"`
+	Scheduled time.Time       `json:"scheduled"`
+	Started   time.Time       `json:"started"`
+	Finished  time.Time       `json:"finished"`
+	Inputs    json.RawMessage `json:"inputs,omitempty"`
+	// Status is the status of the run, or StatusError when it could not be executed.
+	Status   string                     `json:"status"`
+	Error    string                     `json:"error,omitempty"`
+	Response schema.RunWorkflowResponse `json:"response"`
+}
+
+// Succeeded reports whether the run succeeded.
+func (r Record) Succeeded() bool {
+	return r.Status == schema.WorkflowStatusSucceeded
+}
+
+// Options configures a scheduler.
+type Options struct {
+	// Location is the time zone of the cron expressions. Default: time.Local
+	Location *time.Location
+	// Store persists the last run of every job. Without a store, activations missed while the
+	// scheduler was stopped are unknown and never caught up.
+	Store Store
+	// OnResult is called after every run. Jobs run in their own goroutines, so it is called
+	// concurrently for different jobs and must be safe for concurrent use.
+	OnResult func(Record)
+}
+
+// Scheduler runs workflows on cron schedules. The runs of a job never overlap: activations that
+// pass while a run is still running are missed and handled by the catch-up policy of the job.
+type Scheduler struct {
+	opts Options
+	jobs []*entry
+
+	mu      sync.Mutex
+	last    map[string]Record
+	running bool
+}
+
+type entry struct {
+	job      Job
+	schedule *Schedule
+	inputs   map[string]any // string inputs are replaced by their *template.Template
+}
+
+// New creates a scheduler without jobs.
+func New(opts Options) *Scheduler {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	return &Scheduler{opts: opts, last: map[string]Record{}}
+}
+
+// Add adds a job, checking its schedule and input templates. Jobs must be added before Run.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return errors.New("job name is required")
+	}
+	if job.Workflow == nil {
+		return fmt.Errorf("job %s: workflow is required", job.Name)
+	}
+	schedule, err := ParseCron(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if schedule.Next(time.Now().In(s.opts.Location)).IsZero() {
+		return fmt.Errorf("job %s: schedule %q never fires", job.Name, job.Schedule)
+	}
+	inputs := make(map[string]any, len(job.Inputs))
+	for name, value := range job.Inputs {
+		text, ok := value.(string)
+		if !ok {
+			inputs[name] = value
+			continue
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return fmt.Errorf("job %s: input %s: %w", job.Name, name, err)
+		}
+		inputs[name] = tmpl
+	}
+	if job.User == "" {
+		job.User = defaultUser
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return errors.New("scheduler is running")
+	}
+	for _, e := range s.jobs {
+		if e.job.Name == job.Name {
+			return fmt.Errorf("job %s already exists", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &entry{job: job, schedule: schedule, inputs: inputs})
+	return nil
+}
+
+// Last returns the record of the last run of a job, and false if it did not run yet.
+func (s *Scheduler) Last(job string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.last[job]
+	return r, ok
+}
+
+// Run runs the jobs until ctx is done or the store fails. Runs interrupted by ctx are not
+// recorded, so they count as missed on the next start.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return errors.New("scheduler is running")
+	}
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	prevs := make([]time.Time, len(s.jobs))
+	for i, e := range s.jobs {
+		if s.opts.Store == nil {
+			continue
+		}
+		r, ok, err := s.opts.Store.Load(e.job.Name)
+		if err != nil {
+			return fmt.Errorf("failed to load last run of job %s: %w", e.job.Name, err)
+		}
+		if ok {
+			prevs[i] = r.Scheduled
+			s.mu.Lock()
+			s.last[e.job.Name] = r
+			s.mu.Unlock()
+		}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	for i, e := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.loop(ctx, e, prevs[i]); err != nil {
+				cancel(err)
+			}
+		}()
+	}
+	wg.Wait()
+	<-ctx.Done()
+	return context.Cause(ctx)
+}
+
+// loop runs a job on its schedule. prev is the activation of its last run, zero if unknown.
+func (s *Scheduler) loop(ctx context.Context, e *entry, prev time.Time) error {
+	cursor := prev
+	if cursor.IsZero() {
+		cursor = time.Now()
+	}
+	for {
+		now := time.Now().In(s.opts.Location)
+		next := e.schedule.Next(cursor.In(s.opts.Location))
+		if next.IsZero() {
+			// The schedule never fires again.
+			<-ctx.Done()
+			return nil
+		}
+		if !next.After(now) {
+			due := e.due(next, now)
+			switch e.job.CatchUp {
+			case CatchUpLatest:
+				next = due[len(due)-1]
+			case CatchUpAll:
+				next = due[0]
+			default:
+				cursor = due[len(due)-1]
+				continue
+			}
+		}
+
+		delay := time.Until(next)
+		if e.job.Jitter > 0 {
+			delay += rand.N(e.job.Jitter)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		r, ok := s.run(ctx, e, next, prev)
+		if !ok {
+			return nil
+		}
+		if s.opts.Store != nil {
+			if err := s.opts.Store.Save(r); err != nil {
+				return fmt.Errorf("failed to save last run of job %s: %w", e.job.Name, err)
+			}
+		}
+		if s.opts.OnResult != nil {
+			s.opts.OnResult(r)
+		}
+		prev, cursor = next, next
+	}
+}
+
+// due returns the activations from next up to now, keeping the latest maxCatchUp.
+func (e *entry) due(next, now time.Time) []time.Time {
+	var due []time.Time
+	for t := next; !t.IsZero() && !t.After(now); t = e.schedule.Next(t) {
+		if len(due) == maxCatchUp {
+			due = due[1:]
+		}
+		due = append(due, t)
+	}
+	return due
+}
+
+// run executes the run of an activation. It returns false if the run was interrupted by ctx.
+func (s *Scheduler) run(ctx context.Context, e *entry, scheduled, prev time.Time) (Record, bool) {
+	r := Record{Job: e.job.Name, Scheduled: scheduled, Started: time.Now()}
+	inputs, err := e.render(TemplateData{Job: e.job.Name, Time: scheduled, Prev: prev})
+	if err == nil {
+		r.Inputs = inputs
+		runCtx := ctx
+		if e.job.Timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+			defer cancel()
+		}
+		r.Response, err = e.job.Workflow.Run(runCtx, schema.RunWorkflowRequest{
+			Inputs:       inputs,
+			ResponseMode: v1.BlockingMode,
+			User:         e.job.User,
+			Files:        e.job.Files,
+		})
+	}
+	r.Finished = time.Now()
+	if ctx.Err() != nil {
+		return Record{}, false
+	}
+
+	switch {
+	case err != nil:
+		r.Status, r.Error = StatusError, err.Error()
+	default:
+		r.Status, r.Error = r.Response.Data.Status, r.Response.Data.Error
+	}
+	s.mu.Lock()
+	s.last[e.job.Name] = r
+	s.mu.Unlock()
+	return r, true
+}
+
+// render executes the input templates.
+func (e *entry) render(data TemplateData) (json.RawMessage, error) {
+	inputs := make(map[string]any, len(e.inputs))
+	for name, value := range e.inputs {
+		tmpl, ok := value.(*template.Template)
+		if !ok {
+			inputs[name] = value
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		inputs[name] = buf.String()
+	}
+	return json.Marshal(inputs)
+}
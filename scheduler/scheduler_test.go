@@ -0,0 +1,134 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2025, time.January, 31, 10, 7, 30, 0, time.UTC) // a Friday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.January, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, time.January, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2025, time.February, 3, 9, 0, 0, 0, time.UTC)},
+		{"30 8 1,15 * *", time.Date(2025, time.February, 1, 8, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * 5", time.Date(2025, time.January, 31, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, s.Next(from), tt.expr)
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+type fakeRunner struct {
+	mu     sync.Mutex
+	inputs []map[string]any
+}
+
+func (f *fakeRunner) Run(_ context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error) {
+	var inputs map[string]any
+	if err := json.Unmarshal(req.Inputs, &inputs); err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inputs = append(f.inputs, inputs)
+	return schema.RunWorkflowResponse{Data: schema.RunWorkflowResponseData{Status: schema.WorkflowStatusSucceeded}}, nil
+}
+
+func TestCatchUp(t *testing.T) {
+	base := time.Now().UTC().Truncate(time.Minute)
+	tests := []struct {
+		policy CatchUp
+		runs   int
+		first  time.Time
+	}{
+		{CatchUpAll, 3, base.Add(-2 * time.Minute)},
+		{CatchUpLatest, 1, base},
+	}
+	for _, tt := range tests {
+		store, err := OpenFileStore(filepath.Join(t.TempDir(), "runs.json"))
+		require.NoError(t, err)
+		require.NoError(t, store.Save(Record{Job: "report", Scheduled: base.Add(-3 * time.Minute)}))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		var records []Record
+		s := New(Options{
+			Location: time.UTC,
+			Store:    store,
+			OnResult: func(r Record) {
+				records = append(records, r)
+				if len(records) == tt.runs {
+					cancel()
+				}
+			},
+		})
+		runner := &fakeRunner{}
+		require.NoError(t, s.Add(Job{
+			Name:     "report",
+			Schedule: "* * * * *",
+			Workflow: runner,
+			Inputs:   map[string]any{"period": "{{.Prev.Format \"15:04\"}}-{{.Time.Format \"15:04\"}}", "limit": 10},
+			CatchUp:  tt.policy,
+		}))
+		require.ErrorIs(t, s.Run(ctx), context.Canceled)
+
+		require.Len(t, records, tt.runs)
+		assert.Equal(t, tt.first, records[0].Scheduled)
+		assert.True(t, records[0].Succeeded())
+		prev := base.Add(-3 * time.Minute)
+		assert.Equal(t, map[string]any{
+			"period": prev.Format("15:04") + "-" + tt.first.Format("15:04"),
+			"limit":  float64(10),
+		}, runner.inputs[0])
+
+		last, ok := s.Last("report")
+		require.True(t, ok)
+		saved, ok, err := store.Load("report")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, last.Scheduled, saved.Scheduled)
+
+		reopened, err := OpenFileStore(store.name)
+		require.NoError(t, err)
+		saved, _, err = reopened.Load("report")
+		require.NoError(t, err)
+		assert.True(t, last.Scheduled.Equal(saved.Scheduled))
+	}
+}
+
+func TestAdd(t *testing.T) {
+	s := New(Options{})
+	runner := &fakeRunner{}
+	require.NoError(t, s.Add(Job{Name: "a", Schedule: "@daily", Workflow: runner}))
+	assert.Error(t, s.Add(Job{Name: "a", Schedule: "@daily", Workflow: runner}))
+	assert.Error(t, s.Add(Job{Name: "b", Schedule: "@often", Workflow: runner}))
+	assert.Error(t, s.Add(Job{Name: "c", Schedule: "@daily", Workflow: runner, Inputs: map[string]any{"x": "{{.Time"}}))
+	assert.Error(t, s.Add(Job{Name: "d", Schedule: "@daily"}))
+	assert.Error(t, s.Add(Job{Name: "e", Schedule: "0 0 30 2 *", Workflow: runner}))
+}
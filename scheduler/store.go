@@ -0,0 +1,85 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the last run of every job, so that a restarted scheduler knows which runs it
+// missed while it was stopped.
+type Store interface {
+	// Load returns the last recorded run of a job, and false if the job never ran.
+	Load(job string) (Record, bool, error)
+	// Save records the last run of a job.
+	Save(r Record) error
+}
+
+// FileStore is a Store keeping the last runs in a JSON file, rewritten on every save.
+type FileStore struct {
+	name string
+
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+var _ Store = (*FileStore)(nil)
+
+// OpenFileStore opens the store kept in the file name, which is created on the first save.
+func OpenFileStore(name string) (*FileStore, error) {
+	s := &FileStore{name: name, records: map[string]Record{}}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load implements Store.
+func (s *FileStore) Load(job string) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[job]
+	return r, ok, nil
+}
+
+// Save implements Store. The file is replaced atomically, so a crash never leaves it half written.
+func (s *FileStore) Save(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[r.Job] = r
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.name), filepath.Base(s.name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.name)
+}
@@ -0,0 +1,63 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+// Annotation reply actions.
+const (
+	AnnotationReplyEnable  = "enable"
+	AnnotationReplyDisable = "disable"
+)
+
+// Annotation reply job statuses.
+const (
+	JobStatusWaiting    = "waiting"
+	JobStatusProcessing = "processing"
+	JobStatusCompleted  = "completed"
+	JobStatusError      = "error"
+)
+
+// Annotation is a curated question and answer pair used for annotation reply.
+type Annotation struct {
+	ID        string `json:"id"`
+	Question  string `json:"question"`
+	Answer    string `json:"answer"`
+	HitCount  int    `json:"hit_count"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// AnnotationListQuery represents the query parameters for listing annotations.
+type AnnotationListQuery struct {
+	Page    int    `url:"page,omitempty"`
+	Limit   int    `url:"limit,omitempty"`
+	Keyword string `url:"keyword,omitempty"`
+}
+
+// AnnotationListResponse represents a page of annotations.
+type AnnotationListResponse struct {
+	Data    []Annotation `json:"data"`
+	HasMore bool         `json:"has_more"`
+	Limit   int          `json:"limit"`
+	Total   int          `json:"total"`
+	Page    int          `json:"page"`
+}
+
+// AnnotationRequest is the request body for creating or updating an annotation.
+type AnnotationRequest struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// AnnotationReplyRequest is the request body for enabling annotation reply.
+type AnnotationReplyRequest struct {
+	EmbeddingProviderName string  `json:"embedding_provider_name"`
+	EmbeddingModelName    string  `json:"embedding_model_name"`
+	ScoreThreshold        float64 `json:"score_threshold"`
+}
+
+// AnnotationReplyJob is the status of an asynchronous annotation reply settings job.
+type AnnotationReplyJob struct {
+	JobID     string `json:"job_id"`
+	JobStatus string `json:"job_status"`
+	ErrorMsg  string `json:"error_msg,omitempty"`
+}
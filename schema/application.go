@@ -3,6 +3,31 @@
 
 package schema
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Input form control types used in ApplicationParameters.UserInputForm.
+const (
+	InputTypeTextInput = "text-input"
+	InputTypeParagraph = "paragraph"
+	InputTypeSelect    = "select"
+	InputTypeNumber    = "number"
+	InputTypeCheckbox  = "checkbox"
+	InputTypeFile      = "file"
+	InputTypeFileList  = "file-list"
+)
+
+// Application modes reported by ApplicationInfo.Mode.
+const (
+	AppModeChat         = "chat"
+	AppModeAgentChat    = "agent-chat"
+	AppModeAdvancedChat = "advanced-chat"
+	AppModeCompletion   = "completion"
+	AppModeWorkflow     = "workflow"
+)
+
 // ApplicationParameters represents the parameters for an application.
 type ApplicationParameters struct {
 	OpeningStatement              string           `json:"opening_statement,omitempty"`
@@ -15,3 +40,94 @@ type ApplicationParameters struct {
 	FileUpload                    map[string]any   `json:"file_upload,omitempty"`
 	SystemParameters              map[string]any   `json:"system_parameters,omitempty"`
 }
+
+// InputVariable is a typed view of a single control in the application's input form.
+type InputVariable struct {
+	Type                     string   `json:"-"`
+	Label                    string   `json:"label"`
+	Variable                 string   `json:"variable"`
+	Required                 bool     `json:"required"`
+	MaxLength                int      `json:"max_length"`
+	Default                  any      `json:"default"`
+	Options                  []string `json:"options"`
+	AllowedFileTypes         []string `json:"allowed_file_types"`
+	AllowedFileExtensions    []string `json:"allowed_file_extensions"`
+	AllowedFileUploadMethods []string `json:"allowed_file_upload_methods"`
+}
+
+// InputVariables decodes UserInputForm into typed input variables.
+// Each form entry is an object with a single key naming the control type.
+func (p ApplicationParameters) InputVariables() ([]InputVariable, error) {
+	vars := make([]InputVariable, 0, len(p.UserInputForm))
+	for _, item := range p.UserInputForm {
+		for typ, raw := range item {
+			data, err := json.Marshal(raw)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal %s input: %w", typ, err)
+			}
+			var v InputVariable
+			if err = json.Unmarshal(data, &v); err != nil {
+				return nil, fmt.Errorf("failed to decode %s input: %w", typ, err)
+			}
+			v.Type = typ
+			vars = append(vars, v)
+		}
+	}
+	return vars, nil
+}
+
+// ApplicationInfo represents the basic information of an application.
+type ApplicationInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Mode        string   `json:"mode"`
+	AuthorName  string   `json:"author_name"`
+}
+
+// ApplicationMeta represents the meta information of an application.
+type ApplicationMeta struct {
+	ToolIcons map[string]ToolIcon `json:"tool_icons"`
+}
+
+// ToolIcon is the icon of a tool used by an application. Dify returns either an icon URL
+// or an emoji icon with a background color.
+type ToolIcon struct {
+	URL        string `json:"-"`
+	Content    string `json:"content,omitempty"`
+	Background string `json:"background,omitempty"`
+}
+
+// UnmarshalJSON decodes a tool icon given either as a URL string or as an emoji icon object.
+func (t *ToolIcon) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*t = ToolIcon{URL: url}
+		return nil
+	}
+	type emojiIcon ToolIcon
+	var icon emojiIcon
+	if err := json.Unmarshal(data, &icon); err != nil {
+		return err
+	}
+	*t = ToolIcon(icon)
+	return nil
+}
+
+// ApplicationSite represents the WebApp settings of an application.
+type ApplicationSite struct {
+	Title                  string `json:"title"`
+	ChatColorTheme         string `json:"chat_color_theme"`
+	ChatColorThemeInverted bool   `json:"chat_color_theme_inverted"`
+	IconType               string `json:"icon_type"`
+	Icon                   string `json:"icon"`
+	IconBackground         string `json:"icon_background"`
+	IconURL                string `json:"icon_url"`
+	Description            string `json:"description"`
+	Copyright              string `json:"copyright"`
+	PrivacyPolicy          string `json:"privacy_policy"`
+	CustomDisclaimer       string `json:"custom_disclaimer"`
+	DefaultLanguage        string `json:"default_language"`
+	ShowWorkflowSteps      bool   `json:"show_workflow_steps"`
+	UseIconAsAnswerIcon    bool   `json:"use_icon_as_answer_icon"`
+}
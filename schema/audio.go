@@ -0,0 +1,29 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import "io"
+
+// SpeechToTextRequest is the request for converting an audio file to text.
+type SpeechToTextRequest struct {
+	// File is the audio content.
+	File io.Reader
+	// FileName is the name of the audio file; its extension determines the audio format.
+	FileName string
+	User     string
+}
+
+// SpeechToTextResponse is the response of a speech to text conversion.
+type SpeechToTextResponse struct {
+	Text string `json:"text"`
+}
+
+// TextToAudioRequest is the request for converting text to speech.
+// Either MessageID or Text must be set; MessageID takes precedence.
+type TextToAudioRequest struct {
+	MessageID string `json:"message_id,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Voice     string `json:"voice,omitempty"`
+	User      string `json:"user"`
+}
@@ -0,0 +1,154 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+// Dataset indexing techniques.
+const (
+	IndexingTechniqueHighQuality = "high_quality"
+	IndexingTechniqueEconomy     = "economy"
+)
+
+// Dataset permissions.
+const (
+	DatasetPermissionOnlyMe         = "only_me"
+	DatasetPermissionAllTeamMembers = "all_team_members"
+	DatasetPermissionPartialMembers = "partial_members"
+)
+
+// Retrieval search methods.
+const (
+	SearchMethodKeyword  = "keyword_search"
+	SearchMethodSemantic = "semantic_search"
+	SearchMethodFullText = "full_text_search"
+	SearchMethodHybrid   = "hybrid_search"
+)
+
+// Reranking modes of hybrid search.
+const (
+	RerankingModeModel         = "reranking_model"
+	RerankingModeWeightedScore = "weighted_score"
+)
+
+// Dataset represents a knowledge base.
+type Dataset struct {
+	ID                     string         `json:"id"`
+	Name                   string         `json:"name"`
+	Description            string         `json:"description"`
+	Provider               string         `json:"provider"`
+	Permission             string         `json:"permission"`
+	DataSourceType         string         `json:"data_source_type"`
+	IndexingTechnique      string         `json:"indexing_technique"`
+	AppCount               int            `json:"app_count"`
+	DocumentCount          int            `json:"document_count"`
+	WordCount              int            `json:"word_count"`
+	CreatedBy              string         `json:"created_by"`
+	CreatedAt              int64          `json:"created_at"`
+	UpdatedBy              string         `json:"updated_by"`
+	UpdatedAt              int64          `json:"updated_at"`
+	EmbeddingModel         string         `json:"embedding_model"`
+	EmbeddingModelProvider string         `json:"embedding_model_provider"`
+	EmbeddingAvailable     bool           `json:"embedding_available"`
+	RetrievalModel         RetrievalModel `json:"retrieval_model_dict"`
+	Tags                   []DatasetTag   `json:"tags"`
+	DocForm                string         `json:"doc_form"`
+}
+
+// DatasetTag is a tag attached to datasets.
+type DatasetTag struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	BindingCount int    `json:"binding_count"`
+}
+
+// RetrievalModel holds the retrieval settings of a dataset.
+type RetrievalModel struct {
+	SearchMethod          string            `json:"search_method"`
+	RerankingEnable       bool              `json:"reranking_enable"`
+	RerankingMode         string            `json:"reranking_mode,omitempty"`
+	RerankingModel        *RerankingModel   `json:"reranking_model,omitempty"`
+	Weights               *RetrievalWeights `json:"weights,omitempty"`
+	TopK                  int               `json:"top_k"`
+	ScoreThresholdEnabled bool              `json:"score_threshold_enabled"`
+	ScoreThreshold        float64           `json:"score_threshold"`
+	// MetadataFilteringConditions restricts retrieval to documents whose metadata match. It is only
+	// used by retrieval requests.
+	MetadataFilteringConditions *MetadataFilteringConditions `json:"metadata_filtering_conditions,omitempty"`
+}
+
+// RerankingModel identifies the model used to rerank retrieved segments.
+type RerankingModel struct {
+	RerankingProviderName string `json:"reranking_provider_name"`
+	RerankingModelName    string `json:"reranking_model_name"`
+}
+
+// RetrievalWeights holds the weights of semantic and keyword search in weighted score reranking.
+type RetrievalWeights struct {
+	WeightType     string          `json:"weight_type,omitempty"`
+	VectorSetting  *VectorSetting  `json:"vector_setting,omitempty"`
+	KeywordSetting *KeywordSetting `json:"keyword_setting,omitempty"`
+}
+
+// VectorSetting holds the weight and embedding model of semantic search.
+type VectorSetting struct {
+	VectorWeight          float64 `json:"vector_weight"`
+	EmbeddingProviderName string  `json:"embedding_provider_name"`
+	EmbeddingModelName    string  `json:"embedding_model_name"`
+}
+
+// KeywordSetting holds the weight of keyword search.
+type KeywordSetting struct {
+	KeywordWeight float64 `json:"keyword_weight"`
+}
+
+// CreateDatasetRequest is the request body for creating a dataset.
+type CreateDatasetRequest struct {
+	Name                   string          `json:"name"`
+	Description            string          `json:"description,omitempty"`
+	IndexingTechnique      string          `json:"indexing_technique,omitempty"`
+	Permission             string          `json:"permission,omitempty"`
+	Provider               string          `json:"provider,omitempty"`
+	ExternalKnowledgeAPIID string          `json:"external_knowledge_api_id,omitempty"`
+	ExternalKnowledgeID    string          `json:"external_knowledge_id,omitempty"`
+	EmbeddingModel         string          `json:"embedding_model,omitempty"`
+	EmbeddingModelProvider string          `json:"embedding_model_provider,omitempty"`
+	RetrievalModel         *RetrievalModel `json:"retrieval_model,omitempty"`
+}
+
+// UpdateDatasetRequest is the request body for updating a dataset. Empty fields are left unchanged.
+type UpdateDatasetRequest struct {
+	Name                   string          `json:"name,omitempty"`
+	Description            string          `json:"description,omitempty"`
+	IndexingTechnique      string          `json:"indexing_technique,omitempty"`
+	Permission             string          `json:"permission,omitempty"`
+	EmbeddingModel         string          `json:"embedding_model,omitempty"`
+	EmbeddingModelProvider string          `json:"embedding_model_provider,omitempty"`
+	RetrievalModel         *RetrievalModel `json:"retrieval_model,omitempty"`
+	// PartialMemberList lists the members allowed to access the dataset when Permission is
+	// DatasetPermissionPartialMembers.
+	PartialMemberList []DatasetMember `json:"partial_member_list,omitempty"`
+}
+
+// DatasetMember is a workspace member granted access to a dataset.
+type DatasetMember struct {
+	UserID string `json:"user_id"`
+}
+
+// DatasetListQuery represents the query parameters for listing datasets.
+type DatasetListQuery struct {
+	Keyword    string   `url:"keyword,omitempty"`
+	TagIDs     []string `url:"tag_ids,omitempty"`
+	Page       int      `url:"page,omitempty"`
+	Limit      int      `url:"limit,omitempty"`
+	IncludeAll bool     `url:"include_all,omitempty"`
+}
+
+// DatasetListResponse represents a page of datasets.
+type DatasetListResponse struct {
+	Data    []Dataset `json:"data"`
+	HasMore bool      `json:"has_more"`
+	Limit   int       `json:"limit"`
+	Total   int       `json:"total"`
+	Page    int       `json:"page"`
+}
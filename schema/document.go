@@ -0,0 +1,244 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import "io"
+
+// Process rule modes.
+const (
+	ProcessModeAutomatic    = "automatic"
+	ProcessModeCustom       = "custom"
+	ProcessModeHierarchical = "hierarchical"
+)
+
+// Parent chunk modes of the hierarchical process rule.
+const (
+	ParentModeFullDoc   = "full-doc"
+	ParentModeParagraph = "paragraph"
+)
+
+// Pre-processing rule IDs.
+const (
+	PreProcessingRemoveExtraSpaces = "remove_extra_spaces"
+	PreProcessingRemoveURLsEmails  = "remove_urls_emails"
+)
+
+// Document forms, i.e. how a document is chunked.
+const (
+	DocFormText         = "text_model"
+	DocFormHierarchical = "hierarchical_model"
+	DocFormQA           = "qa_model"
+)
+
+// Document status actions for batch status changes.
+const (
+	DocumentActionEnable    = "enable"
+	DocumentActionDisable   = "disable"
+	DocumentActionArchive   = "archive"
+	DocumentActionUnarchive = "un_archive"
+)
+
+// Metadata modes of a document detail request.
+const (
+	DocumentMetadataAll     = "all"
+	DocumentMetadataOnly    = "only"
+	DocumentMetadataWithout = "without"
+)
+
+// Document represents a document of a dataset.
+type Document struct {
+	ID                   string             `json:"id"`
+	Position             int                `json:"position"`
+	DataSourceType       string             `json:"data_source_type"`
+	DataSourceInfo       map[string]any     `json:"data_source_info"`
+	DatasetProcessRuleID string             `json:"dataset_process_rule_id"`
+	Name                 string             `json:"name"`
+	CreatedFrom          string             `json:"created_from"`
+	CreatedBy            string             `json:"created_by"`
+	CreatedAt            int64              `json:"created_at"`
+	Tokens               int                `json:"tokens"`
+	IndexingStatus       string             `json:"indexing_status"`
+	Error                string             `json:"error"`
+	Enabled              bool               `json:"enabled"`
+	DisabledAt           int64              `json:"disabled_at"`
+	DisabledBy           string             `json:"disabled_by"`
+	Archived             bool               `json:"archived"`
+	DisplayStatus        string             `json:"display_status"`
+	WordCount            int                `json:"word_count"`
+	HitCount             int                `json:"hit_count"`
+	DocForm              string             `json:"doc_form"`
+	DocMetadata          []DocumentMetadata `json:"doc_metadata"`
+}
+
+// DocumentMetadata is a metadata value of a document.
+type DocumentMetadata struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// ProcessRule describes how a document is cleaned and split into segments.
+type ProcessRule struct {
+	Mode  string             `json:"mode"`
+	Rules *ProcessRuleDetail `json:"rules,omitempty"`
+}
+
+// ProcessRuleDetail holds the rules of custom and hierarchical process rules.
+type ProcessRuleDetail struct {
+	PreProcessingRules   []PreProcessingRule `json:"pre_processing_rules,omitempty"`
+	Segmentation         *Segmentation       `json:"segmentation,omitempty"`
+	ParentMode           string              `json:"parent_mode,omitempty"`
+	SubchunkSegmentation *Segmentation       `json:"subchunk_segmentation,omitempty"`
+}
+
+// PreProcessingRule enables or disables a cleaning step.
+type PreProcessingRule struct {
+	ID      string `json:"id"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Segmentation configures how text is split into chunks.
+type Segmentation struct {
+	Separator    string `json:"separator"`
+	MaxTokens    int    `json:"max_tokens"`
+	ChunkOverlap int    `json:"chunk_overlap,omitempty"`
+}
+
+// DocumentSettings holds the indexing settings shared by document creation requests.
+type DocumentSettings struct {
+	IndexingTechnique      string          `json:"indexing_technique,omitempty"`
+	DocForm                string          `json:"doc_form,omitempty"`
+	DocLanguage            string          `json:"doc_language,omitempty"`
+	ProcessRule            *ProcessRule    `json:"process_rule,omitempty"`
+	RetrievalModel         *RetrievalModel `json:"retrieval_model,omitempty"`
+	EmbeddingModel         string          `json:"embedding_model,omitempty"`
+	EmbeddingModelProvider string          `json:"embedding_model_provider,omitempty"`
+}
+
+// CreateDocumentByTextRequest is the request body for creating a document from text.
+type CreateDocumentByTextRequest struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+	DocumentSettings
+}
+
+// CreateDocumentByFileRequest is the request for creating a document from a file.
+type CreateDocumentByFileRequest struct {
+	File     io.Reader `json:"-"`
+	FileName string    `json:"-"`
+	DocumentSettings
+	// OriginalDocumentID replaces an existing document with the uploaded file when set.
+	OriginalDocumentID string `json:"original_document_id,omitempty"`
+}
+
+// UpdateDocumentByTextRequest is the request body for updating a document with text.
+type UpdateDocumentByTextRequest struct {
+	Name        string       `json:"name,omitempty"`
+	Text        string       `json:"text,omitempty"`
+	ProcessRule *ProcessRule `json:"process_rule,omitempty"`
+}
+
+// UpdateDocumentByFileRequest is the request for updating a document with a file.
+type UpdateDocumentByFileRequest struct {
+	File        io.Reader    `json:"-"`
+	FileName    string       `json:"-"`
+	Name        string       `json:"name,omitempty"`
+	ProcessRule *ProcessRule `json:"process_rule,omitempty"`
+}
+
+// DocumentResponse is the response of creating or updating a document. Batch identifies the
+// indexing job started for the document.
+type DocumentResponse struct {
+	Document Document `json:"document"`
+	Batch    string   `json:"batch"`
+}
+
+// Document indexing statuses.
+const (
+	IndexingStatusWaiting   = "waiting"
+	IndexingStatusParsing   = "parsing"
+	IndexingStatusCleaning  = "cleaning"
+	IndexingStatusSplitting = "splitting"
+	IndexingStatusIndexing  = "indexing"
+	IndexingStatusPaused    = "paused"
+	IndexingStatusCompleted = "completed"
+	IndexingStatusError     = "error"
+)
+
+// IndexingStatus is the indexing progress of a document.
+type IndexingStatus struct {
+	ID                   string  `json:"id"`
+	IndexingStatus       string  `json:"indexing_status"`
+	ProcessingStartedAt  float64 `json:"processing_started_at"`
+	ParsingCompletedAt   float64 `json:"parsing_completed_at"`
+	CleaningCompletedAt  float64 `json:"cleaning_completed_at"`
+	SplittingCompletedAt float64 `json:"splitting_completed_at"`
+	CompletedAt          float64 `json:"completed_at"`
+	PausedAt             float64 `json:"paused_at"`
+	Error                string  `json:"error"`
+	StoppedAt            float64 `json:"stopped_at"`
+	CompletedSegments    int     `json:"completed_segments"`
+	TotalSegments        int     `json:"total_segments"`
+}
+
+// IndexingStatusResponse holds the indexing progress of the documents of a batch.
+type IndexingStatusResponse struct {
+	Data []IndexingStatus `json:"data"`
+}
+
+// DocumentListQuery represents the query parameters for listing documents.
+type DocumentListQuery struct {
+	Keyword string `url:"keyword,omitempty"`
+	Status  string `url:"status,omitempty"`
+	Page    int    `url:"page,omitempty"`
+	Limit   int    `url:"limit,omitempty"`
+}
+
+// DocumentListResponse represents a page of documents.
+type DocumentListResponse struct {
+	Data    []Document `json:"data"`
+	HasMore bool       `json:"has_more"`
+	Limit   int        `json:"limit"`
+	Total   int        `json:"total"`
+	Page    int        `json:"page"`
+}
+
+// DocumentDetailQuery represents the query parameters for retrieving a document.
+type DocumentDetailQuery struct {
+	// Metadata selects whether the metadata, the document or both are returned.
+	Metadata string `url:"metadata,omitempty"`
+}
+
+// DocumentDetail is a document with its processing settings and segment statistics.
+type DocumentDetail struct {
+	Document
+	DatasetProcessRule   *ProcessRule `json:"dataset_process_rule"`
+	DocumentProcessRule  *ProcessRule `json:"document_process_rule"`
+	DocType              string       `json:"doc_type"`
+	DocLanguage          string       `json:"doc_language"`
+	SegmentCount         int          `json:"segment_count"`
+	AverageSegmentLength float64      `json:"average_segment_length"`
+	CompletedAt          int64        `json:"completed_at"`
+	UpdatedAt            int64        `json:"updated_at"`
+	IndexingLatency      float64      `json:"indexing_latency"`
+}
+
+// DocumentStatusRequest is the request body for changing the status of documents.
+type DocumentStatusRequest struct {
+	DocumentIDs []string `json:"document_ids"`
+}
+
+// DocumentUploadFile is the original file a document was created from.
+type DocumentUploadFile struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	Extension   string `json:"extension"`
+	URL         string `json:"url"`
+	DownloadURL string `json:"download_url"`
+	MimeType    string `json:"mime_type"`
+	CreatedBy   string `json:"created_by"`
+	CreatedAt   int64  `json:"created_at"`
+}
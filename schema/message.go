@@ -0,0 +1,68 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import "encoding/json"
+
+// ChatMessageRequest represents the request body for sending a message to a chat app.
+type ChatMessageRequest struct {
+	Query        string          `json:"query"`
+	Inputs       json.RawMessage `json:"inputs"`
+	ResponseMode string          `json:"response_mode"`
+	User         string          `json:"user"`
+	// ConversationID continues a conversation; empty starts a new one.
+	ConversationID   string                   `json:"conversation_id,omitempty"`
+	Files            []RunWorkflowRequestFile `json:"files,omitempty"`
+	AutoGenerateName *bool                    `json:"auto_generate_name,omitempty"`
+}
+
+// CompletionMessageRequest represents the request body for sending a message to a completion app.
+// The prompt variables, including query, are passed in Inputs.
+type CompletionMessageRequest struct {
+	Inputs       json.RawMessage          `json:"inputs"`
+	ResponseMode string                   `json:"response_mode"`
+	User         string                   `json:"user"`
+	Files        []RunWorkflowRequestFile `json:"files,omitempty"`
+}
+
+// MessageResponse represents the blocking response of a chat or completion app.
+type MessageResponse struct {
+	Event          string          `json:"event"`
+	TaskID         string          `json:"task_id"`
+	ID             string          `json:"id"`
+	MessageID      string          `json:"message_id"`
+	ConversationID string          `json:"conversation_id"`
+	Mode           string          `json:"mode"`
+	Answer         string          `json:"answer"`
+	Metadata       MessageMetadata `json:"metadata"`
+	CreatedAt      int64           `json:"created_at"`
+}
+
+// MessageMetadata contains the usage and the knowledge sources of a message.
+type MessageMetadata struct {
+	Usage              Usage               `json:"usage"`
+	RetrieverResources []RetrieverResource `json:"retriever_resources"`
+}
+
+// Usage reports the model usage of a message.
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	TotalPrice       string  `json:"total_price"`
+	Currency         string  `json:"currency"`
+	Latency          float64 `json:"latency"`
+}
+
+// RetrieverResource is a knowledge base segment cited by a message.
+type RetrieverResource struct {
+	Position     int     `json:"position"`
+	DatasetID    string  `json:"dataset_id"`
+	DatasetName  string  `json:"dataset_name"`
+	DocumentID   string  `json:"document_id"`
+	DocumentName string  `json:"document_name"`
+	SegmentID    string  `json:"segment_id"`
+	Score        float64 `json:"score"`
+	Content      string  `json:"content"`
+}
@@ -0,0 +1,61 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+// Metadata field types. Time values are Unix timestamps in seconds.
+const (
+	MetadataTypeString = "string"
+	MetadataTypeNumber = "number"
+	MetadataTypeTime   = "time"
+)
+
+// Actions toggling the built-in metadata fields of a dataset.
+const (
+	BuiltInMetadataEnable  = "enable"
+	BuiltInMetadataDisable = "disable"
+)
+
+// MetadataField is a custom metadata field of a dataset.
+type MetadataField struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Count is the number of documents using the field. It is only set when listing fields.
+	Count int `json:"count"`
+}
+
+// CreateMetadataFieldRequest is the request body for creating a metadata field.
+type CreateMetadataFieldRequest struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// RenameMetadataFieldRequest is the request body for renaming a metadata field.
+type RenameMetadataFieldRequest struct {
+	Name string `json:"name"`
+}
+
+// MetadataFieldListResponse holds the metadata fields of a dataset.
+type MetadataFieldListResponse struct {
+	DocMetadata         []MetadataField `json:"doc_metadata"`
+	BuiltInFieldEnabled bool            `json:"built_in_field_enabled"`
+}
+
+// DocumentMetadataOperation replaces the metadata values of a document.
+type DocumentMetadataOperation struct {
+	DocumentID   string          `json:"document_id"`
+	MetadataList []MetadataValue `json:"metadata_list"`
+}
+
+// MetadataValue is the value of a metadata field for a document.
+type MetadataValue struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// UpdateDocumentMetadataRequest is the request body for assigning metadata values to documents.
+type UpdateDocumentMetadataRequest struct {
+	OperationData []DocumentMetadataOperation `json:"operation_data"`
+}
@@ -0,0 +1,53 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+// Model types that can be listed for the workspace.
+const (
+	ModelTypeTextEmbedding = "text-embedding"
+	ModelTypeRerank        = "rerank"
+)
+
+// Model statuses. Only active models can be used.
+const (
+	ModelStatusActive        = "active"
+	ModelStatusNoConfigure   = "no-configure"
+	ModelStatusQuotaExceeded = "quota-exceeded"
+	ModelStatusNoPermission  = "no-permission"
+	ModelStatusDisabled      = "disabled"
+)
+
+// I18nText is a text translated in several languages.
+type I18nText struct {
+	EnUS   string `json:"en_US"`
+	ZhHans string `json:"zh_Hans"`
+}
+
+// ModelProvider is a model provider of the workspace with its models of the requested type.
+type ModelProvider struct {
+	Provider  string          `json:"provider"`
+	Label     I18nText        `json:"label"`
+	IconSmall I18nText        `json:"icon_small"`
+	IconLarge I18nText        `json:"icon_large"`
+	Status    string          `json:"status"`
+	Models    []ProviderModel `json:"models"`
+}
+
+// ProviderModel is a model of a provider.
+type ProviderModel struct {
+	Model                string         `json:"model"`
+	Label                I18nText       `json:"label"`
+	ModelType            string         `json:"model_type"`
+	Features             []string       `json:"features"`
+	FetchFrom            string         `json:"fetch_from"`
+	ModelProperties      map[string]any `json:"model_properties"`
+	Deprecated           bool           `json:"deprecated"`
+	Status               string         `json:"status"`
+	LoadBalancingEnabled bool           `json:"load_balancing_enabled"`
+}
+
+// ModelListResponse holds the model providers of the workspace.
+type ModelListResponse struct {
+	Data []ModelProvider `json:"data"`
+}
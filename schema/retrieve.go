@@ -0,0 +1,93 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+// Logical operators combining metadata filtering conditions.
+const (
+	LogicalOperatorAnd = "and"
+	LogicalOperatorOr  = "or"
+)
+
+// Comparison operators of metadata filtering conditions. The string operators apply to string fields,
+// the numeric ones to number fields and before/after to time fields.
+const (
+	ComparisonContains    = "contains"
+	ComparisonNotContains = "not contains"
+	ComparisonStartWith   = "start with"
+	ComparisonEndWith     = "end with"
+	ComparisonIs          = "is"
+	ComparisonIsNot       = "is not"
+	ComparisonEmpty       = "empty"
+	ComparisonNotEmpty    = "not empty"
+	ComparisonEqual       = "="
+	ComparisonNotEqual    = "≠"
+	ComparisonGreater     = ">"
+	ComparisonLess        = "<"
+	ComparisonGreaterEq   = "≥"
+	ComparisonLessEq      = "≤"
+	ComparisonBefore      = "before"
+	ComparisonAfter       = "after"
+)
+
+// MetadataFilteringConditions filters retrieved documents by their metadata.
+type MetadataFilteringConditions struct {
+	LogicalOperator string              `json:"logical_operator"`
+	Conditions      []MetadataCondition `json:"conditions"`
+}
+
+// MetadataCondition compares a metadata field with a value. Value is omitted for the empty and
+// not empty operators.
+type MetadataCondition struct {
+	Name               string `json:"name"`
+	ComparisonOperator string `json:"comparison_operator"`
+	Value              any    `json:"value,omitempty"`
+}
+
+// RetrieveRequest is the request body for retrieving segments from a dataset. The retrieval settings
+// of the dataset are used when RetrievalModel is nil.
+type RetrieveRequest struct {
+	Query          string          `json:"query"`
+	RetrievalModel *RetrievalModel `json:"retrieval_model,omitempty"`
+}
+
+// RetrieveResponse holds the segments matching a query, ordered by score.
+type RetrieveResponse struct {
+	Query   RetrieveQuery     `json:"query"`
+	Records []RetrievalRecord `json:"records"`
+}
+
+// RetrieveQuery echoes the query of a retrieval.
+type RetrieveQuery struct {
+	Content string `json:"content"`
+}
+
+// RetrievalRecord is a segment matching a query.
+type RetrievalRecord struct {
+	Segment RetrievedSegment `json:"segment"`
+	// ChildChunks holds the matching child chunks of parent-child documents.
+	ChildChunks []RetrievedChildChunk `json:"child_chunks"`
+	Score       float64               `json:"score"`
+}
+
+// RetrievedSegment is a retrieved segment with the document it belongs to.
+type RetrievedSegment struct {
+	Segment
+	Document RetrievedDocument `json:"document"`
+}
+
+// RetrievedDocument holds the document information of a retrieved segment.
+type RetrievedDocument struct {
+	ID             string `json:"id"`
+	DataSourceType string `json:"data_source_type"`
+	Name           string `json:"name"`
+	DocType        string `json:"doc_type"`
+}
+
+// RetrievedChildChunk is a child chunk matching a query.
+type RetrievedChildChunk struct {
+	ID       string  `json:"id"`
+	Content  string  `json:"content"`
+	Position int     `json:"position"`
+	Score    float64 `json:"score"`
+}
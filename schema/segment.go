@@ -0,0 +1,125 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+// Segment is a chunk of a document.
+type Segment struct {
+	ID            string       `json:"id"`
+	Position      int          `json:"position"`
+	DocumentID    string       `json:"document_id"`
+	Content       string       `json:"content"`
+	Answer        string       `json:"answer"`
+	WordCount     int          `json:"word_count"`
+	Tokens        int          `json:"tokens"`
+	Keywords      []string     `json:"keywords"`
+	IndexNodeID   string       `json:"index_node_id"`
+	IndexNodeHash string       `json:"index_node_hash"`
+	HitCount      int          `json:"hit_count"`
+	Enabled       bool         `json:"enabled"`
+	DisabledAt    int64        `json:"disabled_at"`
+	DisabledBy    string       `json:"disabled_by"`
+	Status        string       `json:"status"`
+	CreatedBy     string       `json:"created_by"`
+	CreatedAt     int64        `json:"created_at"`
+	IndexingAt    int64        `json:"indexing_at"`
+	CompletedAt   int64        `json:"completed_at"`
+	Error         string       `json:"error"`
+	StoppedAt     int64        `json:"stopped_at"`
+	ChildChunks   []ChildChunk `json:"child_chunks,omitempty"`
+}
+
+// ChildChunk is a child chunk of a segment in a parent-child (hierarchical) document.
+type ChildChunk struct {
+	ID        string `json:"id"`
+	SegmentID string `json:"segment_id"`
+	Content   string `json:"content"`
+	Position  int    `json:"position"`
+	WordCount int    `json:"word_count"`
+	Type      string `json:"type"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// SegmentListQuery represents the query parameters for listing segments.
+type SegmentListQuery struct {
+	Keyword string `url:"keyword,omitempty"`
+	Status  string `url:"status,omitempty"`
+	Page    int    `url:"page,omitempty"`
+	Limit   int    `url:"limit,omitempty"`
+}
+
+// SegmentListResponse represents a page of segments.
+type SegmentListResponse struct {
+	Data    []Segment `json:"data"`
+	DocForm string    `json:"doc_form"`
+	HasMore bool      `json:"has_more"`
+	Limit   int       `json:"limit"`
+	Total   int       `json:"total"`
+	Page    int       `json:"page"`
+}
+
+// NewSegment is a segment to add to a document. Answer is only used by Q&A documents.
+type NewSegment struct {
+	Content  string   `json:"content"`
+	Answer   string   `json:"answer,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// CreateSegmentsRequest is the request body for adding segments to a document.
+type CreateSegmentsRequest struct {
+	Segments []NewSegment `json:"segments"`
+}
+
+// CreateSegmentsResponse holds the added segments.
+type CreateSegmentsResponse struct {
+	Data    []Segment `json:"data"`
+	DocForm string    `json:"doc_form"`
+}
+
+// SegmentUpdate holds the new values of a segment.
+type SegmentUpdate struct {
+	Content  string   `json:"content"`
+	Answer   string   `json:"answer,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	Enabled  *bool    `json:"enabled,omitempty"`
+	// RegenerateChildChunks re-splits the child chunks of a parent-child document.
+	RegenerateChildChunks bool `json:"regenerate_child_chunks,omitempty"`
+}
+
+// UpdateSegmentRequest is the request body for updating a segment.
+type UpdateSegmentRequest struct {
+	Segment SegmentUpdate `json:"segment"`
+}
+
+// SegmentResponse holds a single segment.
+type SegmentResponse struct {
+	Data    Segment `json:"data"`
+	DocForm string  `json:"doc_form"`
+}
+
+// ChildChunkListQuery represents the query parameters for listing child chunks.
+type ChildChunkListQuery struct {
+	Keyword string `url:"keyword,omitempty"`
+	Page    int    `url:"page,omitempty"`
+	Limit   int    `url:"limit,omitempty"`
+}
+
+// ChildChunkListResponse represents a page of child chunks.
+type ChildChunkListResponse struct {
+	Data       []ChildChunk `json:"data"`
+	Total      int          `json:"total"`
+	TotalPages int          `json:"total_pages"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+}
+
+// ChildChunkRequest is the request body for creating or updating a child chunk.
+type ChildChunkRequest struct {
+	Content string `json:"content"`
+}
+
+// ChildChunkResponse holds a single child chunk.
+type ChildChunkResponse struct {
+	Data ChildChunk `json:"data"`
+}
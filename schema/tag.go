@@ -0,0 +1,38 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+// CreateTagRequest is the request body for creating a dataset tag.
+type CreateTagRequest struct {
+	Name string `json:"name"`
+}
+
+// RenameTagRequest is the request body for renaming a dataset tag.
+type RenameTagRequest struct {
+	TagID string `json:"tag_id"`
+	Name  string `json:"name"`
+}
+
+// DeleteTagRequest is the request body for deleting a dataset tag.
+type DeleteTagRequest struct {
+	TagID string `json:"tag_id"`
+}
+
+// BindTagsRequest is the request body for attaching tags to a dataset.
+type BindTagsRequest struct {
+	TagIDs   []string `json:"tag_ids"`
+	TargetID string   `json:"target_id"`
+}
+
+// UnbindTagRequest is the request body for detaching a tag from a dataset.
+type UnbindTagRequest struct {
+	TagID    string `json:"tag_id"`
+	TargetID string `json:"target_id"`
+}
+
+// DatasetTagsResponse holds the tags attached to a dataset.
+type DatasetTagsResponse struct {
+	Data  []DatasetTag `json:"data"`
+	Total int          `json:"total"`
+}
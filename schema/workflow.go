@@ -5,6 +5,27 @@ package schema
 
 import "encoding/json"
 
+// Workflow run statuses reported by dify.
+const (
+	WorkflowStatusRunning   = "running"
+	WorkflowStatusSucceeded = "succeeded"
+	WorkflowStatusFailed    = "failed"
+	WorkflowStatusStopped   = "stopped"
+)
+
+// Event types emitted by a streaming workflow run.
+const (
+	EventWorkflowStarted  = "workflow_started"
+	EventWorkflowFinished = "workflow_finished"
+	EventNodeStarted      = "node_started"
+	EventNodeFinished     = "node_finished"
+	EventTextChunk        = "text_chunk"
+	EventTTSMessage       = "tts_message"
+	EventTTSMessageEnd    = "tts_message_end"
+	EventPing             = "ping"
+	EventError            = "error"
+)
+
 // RunWorkflowRequestFile represents a file included in a workflow run request.
 type RunWorkflowRequestFile struct {
 	Type           string `json:"type"`
@@ -43,6 +64,58 @@ type RunWorkflowResponseData struct {
 	FinishedAt  int            `json:"finished_at"`
 }
 
+// WorkflowStreamEvent represents a single event emitted by a streaming workflow run.
+// Data holds the event specific payload and is decoded according to Event.
+type WorkflowStreamEvent struct {
+	Event         string          `json:"event"`
+	TaskID        string          `json:"task_id"`
+	WorkflowRunID string          `json:"workflow_run_id"`
+	Data          json.RawMessage `json:"data"`
+	// Status, Code and Message are only set on error events.
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NodeEventData is the payload of node_started and node_finished events. The outputs, status,
+// error and elapsed time are only set on node_finished.
+type NodeEventData struct {
+	ID                string         `json:"id"`
+	NodeID            string         `json:"node_id"`
+	NodeType          string         `json:"node_type"`
+	Title             string         `json:"title"`
+	Index             int            `json:"index"`
+	PredecessorNodeID string         `json:"predecessor_node_id"`
+	Inputs            map[string]any `json:"inputs"`
+	Outputs           map[string]any `json:"outputs"`
+	Status            string         `json:"status"`
+	Error             string         `json:"error"`
+	ElapsedTime       float64        `json:"elapsed_time"`
+	CreatedAt         int64          `json:"created_at"`
+	FinishedAt        int64          `json:"finished_at"`
+}
+
+// WorkflowRun represents a workflow run retrieved by ID. Inputs and Outputs hold JSON objects,
+// which older dify versions encode as strings.
+type WorkflowRun struct {
+	ID          string          `json:"id"`
+	WorkflowID  string          `json:"workflow_id"`
+	Status      string          `json:"status"`
+	Inputs      json.RawMessage `json:"inputs"`
+	Outputs     json.RawMessage `json:"outputs"`
+	Error       string          `json:"error"`
+	TotalSteps  int             `json:"total_steps"`
+	TotalTokens int             `json:"total_tokens"`
+	CreatedAt   int             `json:"created_at"`
+	FinishedAt  int             `json:"finished_at"`
+	ElapsedTime float64         `json:"elapsed_time"`
+}
+
+// StopWorkflowRequest is the request body for stopping a streaming workflow run.
+type StopWorkflowRequest struct {
+	User string `json:"user"`
+}
+
 // WorkflowRunLogQuery represents the query parameters for workflow run logs.
 type WorkflowRunLogQuery struct {
 	Keyword                   string `url:"keyword"`
@@ -0,0 +1,20 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package workflowtest runs regression suites against a published workflow. A suite is a YAML file
+// of cases, each with inputs and the outputs expected from the run:
+//
+//	user: regression
+//	cases:
+//	  - name: greets the user
+//	    inputs: {name: Bob}
+//	    expect:
+//	      outputs:
+//	        greeting: Hello Bob
+//	        summary: {contains: Bob, regex: "^[A-Z]"}
+//	        result.items[0].score: {number: 0.8, tolerance: 0.05}
+//
+// Output keys are paths into the outputs. A scalar or list expectation is compared exactly; a mapping
+// selects matchers, which must all pass. Reports are written for terminals, as JSON or as JUnit XML,
+// and Test runs a suite as subtests of a go test.
+package workflowtest
@@ -0,0 +1,234 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package workflowtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pathPattern matches one element of an output path: a key or an index.
+var pathPattern = regexp.MustCompile(`^(?:\.?([^.\[\]]+)|\[(\d+)\])`)
+
+// pathElem is a key, or an index when key is empty.
+type pathElem struct {
+	key   string
+	index int
+}
+
+// parsePath parses paths such as "result.items[0].name", optionally prefixed by "$.".
+func parsePath(path string) ([]pathElem, error) {
+	rest := strings.TrimPrefix(path, "$")
+	var elems []pathElem
+	for rest != "" {
+		m := pathPattern.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid output path %q", path)
+		}
+		if m[1] != "" {
+			elems = append(elems, pathElem{key: m[1]})
+		} else {
+			i, _ := strconv.Atoi(m[2])
+			elems = append(elems, pathElem{index: i})
+		}
+		rest = rest[len(m[0]):]
+	}
+	if len(elems) == 0 {
+		return nil, fmt.Errorf("empty output path %q", path)
+	}
+	return elems, nil
+}
+
+// lookup returns the value at path in outputs.
+func lookup(outputs map[string]any, path string) (any, bool) {
+	elems, err := parsePath(path)
+	if err != nil {
+		return nil, false
+	}
+	var v any = outputs
+	for _, e := range elems {
+		switch c := v.(type) {
+		case map[string]any:
+			if e.key == "" {
+				return nil, false
+			}
+			var ok bool
+			if v, ok = c[e.key]; !ok {
+				return nil, false
+			}
+		case []any:
+			if e.key != "" || e.index >= len(c) {
+				return nil, false
+			}
+			v = c[e.index]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Failure is an expectation that was not met.
+type Failure struct {
+	// Output is the output path, empty for the run status.
+	Output   string `json:"output,omitempty"`
+	Matcher  string `json:"matcher"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	// Diff is a line diff of the expected and actual values, set for multi-line exact mismatches.
+	Diff string `json:"diff,omitempty"`
+}
+
+// String formats the failure for terminals.
+func (f Failure) String() string {
+	target := "status"
+	if f.Output != "" {
+		target = f.Output
+	}
+	if f.Diff != "" {
+		return fmt.Sprintf("%s: %s mismatch:\n%s", target, f.Matcher, f.Diff)
+	}
+	return fmt.Sprintf("%s: %s: expected %s, got %s", target, f.Matcher, f.Expected, f.Actual)
+}
+
+// check returns the failures of value against m.
+func (m Matcher) check(path string, value any, found bool) []Failure {
+	if !found {
+		return []Failure{{Output: path, Matcher: "present", Expected: "a value", Actual: "missing"}}
+	}
+	value = normalize(value)
+	var failures []Failure
+	fail := func(matcher string, expected any) {
+		failures = append(failures, Failure{Output: path, Matcher: matcher, Expected: format(expected), Actual: format(value)})
+	}
+
+	if m.hasExact {
+		expected := normalize(m.Exact)
+		if !reflect.DeepEqual(expected, value) {
+			fail("exact", expected)
+			if e, a := indent(expected), indent(value); strings.Contains(e, "\n") || strings.Contains(a, "\n") {
+				failures[len(failures)-1].Diff = lineDiff(e, a)
+			}
+		}
+	}
+	if m.Contains != nil {
+		expected := normalize(m.Contains)
+		var ok bool
+		switch v := value.(type) {
+		case string:
+			s, isString := expected.(string)
+			ok = isString && strings.Contains(v, s)
+		case []any:
+			ok = slices.ContainsFunc(v, func(e any) bool { return reflect.DeepEqual(e, expected) })
+		}
+		if !ok {
+			fail("contains", expected)
+		}
+	}
+	if m.regex != nil {
+		s, ok := value.(string)
+		if !ok {
+			s = format(value)
+		}
+		if !m.regex.MatchString(s) {
+			fail("regex", m.Regex)
+		}
+	}
+	if m.Number != nil {
+		n, ok := number(value)
+		if !ok || math.Abs(n-*m.Number) > m.Tolerance {
+			fail("number", fmt.Sprintf("%g ± %g", *m.Number, m.Tolerance))
+		}
+	}
+	return failures
+}
+
+// normalize converts v to the values produced by decoding JSON, so that values decoded from YAML
+// compare equal to workflow outputs.
+func normalize(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// number converts a JSON number or a numeric string to a float.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func format(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// indent formats v for diffs: strings as is, other values as indented JSON.
+func indent(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// lineDiff returns a diff of the lines of expected and actual, based on their longest common subsequence.
+func lineDiff(expected, actual string) string {
+	a, b := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString("  " + a[i] + "\n")
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,117 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package workflowtest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteText writes a human readable report with the failures of every case and a summary.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, res := range r.Results {
+		switch {
+		case res.Err != "":
+			fmt.Fprintf(&b, "ERROR %s (%s): %s\n", res.Name, round(res.Elapsed), res.Err)
+		case len(res.Failures) > 0:
+			fmt.Fprintf(&b, "FAIL  %s (%s)\n", res.Name, round(res.Elapsed))
+			for _, f := range res.Failures {
+				b.WriteString("      " + strings.ReplaceAll(strings.TrimRight(f.String(), "\n"), "\n", "\n      ") + "\n")
+			}
+		default:
+			fmt.Fprintf(&b, "PASS  %s (%s)\n", res.Name, round(res.Elapsed))
+		}
+	}
+	passed, failed, errored := r.Counts()
+	fmt.Fprintf(&b, "%s: %d passed, %d failed, %d errors in %s\n", r.Suite, passed, failed, errored, round(r.Elapsed))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     float64     `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes reports as JUnit XML, one test suite per report.
+func WriteJUnit(w io.Writer, reports ...*Report) error {
+	var doc junitSuites
+	for _, r := range reports {
+		_, failed, errored := r.Counts()
+		suite := junitSuite{
+			Name:     r.Suite,
+			Tests:    len(r.Results),
+			Failures: failed,
+			Errors:   errored,
+			Time:     r.Elapsed.Seconds(),
+		}
+		for _, res := range r.Results {
+			c := junitCase{Name: res.Name, ClassName: r.Suite, Time: res.Elapsed.Seconds()}
+			switch {
+			case res.Err != "":
+				c.Error = &junitMessage{Message: res.Err}
+			case len(res.Failures) > 0:
+				msgs := make([]string, 0, len(res.Failures))
+				for _, f := range res.Failures {
+					msgs = append(msgs, f.String())
+				}
+				c.Failure = &junitMessage{
+					Message: fmt.Sprintf("%d expectations failed", len(res.Failures)),
+					Body:    strings.Join(msgs, "\n"),
+				}
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
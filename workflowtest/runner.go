@@ -0,0 +1,170 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package workflowtest
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	v1 "github.com/yeeaiclub/dify-go/client/api/v1"
+	"github.com/yeeaiclub/dify-go/internal/syncutil"
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+// defaultUser is the end user of the runs when the suite does not set one.
+const defaultUser = "workflowtest"
+
+// Runner executes workflows. It is implemented by *v1.WorkflowService.
+type Runner interface {
+	Run(ctx context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error)
+}
+
+var _ Runner = (*v1.WorkflowService)(nil)
+
+// Options configures a suite run.
+type Options struct {
+	// Concurrency is the maximum number of concurrent runs. Default: 4
+	Concurrency int
+	// Filter selects the cases to run by name; every case runs when it is nil.
+	Filter func(name string) bool
+}
+
+// Result is the result of a case.
+type Result struct {
+	Name     string        `json:"name"`
+	RunID    string        `json:"run_id,omitempty"`
+	Status   string        `json:"status,omitempty"`
+	Elapsed  time.Duration `json:"elapsed"`
+	Failures []Failure     `json:"failures,omitempty"`
+	// Err is set when the workflow could not be run.
+	Err string `json:"error,omitempty"`
+}
+
+// Passed reports whether the case ran and met every expectation.
+func (r Result) Passed() bool {
+	return r.Err == "" && len(r.Failures) == 0
+}
+
+// Report holds the results of a suite, in the order of its cases.
+type Report struct {
+	Suite   string        `json:"suite"`
+	Elapsed time.Duration `json:"elapsed"`
+	Results []Result      `json:"results"`
+}
+
+// Passed reports whether every case passed.
+func (r *Report) Passed() bool {
+	for _, res := range r.Results {
+		if !res.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Counts returns the number of passed, failed and errored cases.
+func (r *Report) Counts() (passed, failed, errored int) {
+	for _, res := range r.Results {
+		switch {
+		case res.Err != "":
+			errored++
+		case len(res.Failures) > 0:
+			failed++
+		default:
+			passed++
+		}
+	}
+	return passed, failed, errored
+}
+
+// Run runs the cases of suite with bounded concurrency.
+func Run(ctx context.Context, runner Runner, suite *Suite, opts Options) *Report {
+	user := suite.User
+	if user == "" {
+		user = defaultUser
+	}
+	cases := slices.DeleteFunc(slices.Clone(suite.Cases), func(c Case) bool {
+		return opts.Filter != nil && !opts.Filter(c.Name)
+	})
+
+	start := time.Now()
+	report := &Report{Suite: suite.Name, Results: make([]Result, len(cases))}
+	for i, c := range cases {
+		report.Results[i].Name = c.Name
+	}
+	errs := syncutil.Each(ctx, len(cases), opts.Concurrency, func(ctx context.Context, i int) error {
+		return runCase(ctx, runner, user, cases[i], &report.Results[i])
+	})
+	for i, err := range errs {
+		if err != nil {
+			report.Results[i].Err = err.Error()
+		}
+	}
+	report.Elapsed = time.Since(start)
+	return report
+}
+
+// runCase runs a case and checks its expectations into res. It returns the error preventing the run.
+func runCase(ctx context.Context, runner Runner, user string, c Case, res *Result) error {
+	inputs, err := json.Marshal(normalize(c.Inputs))
+	if err != nil {
+		return err
+	}
+	if c.Inputs == nil {
+		inputs = []byte("{}")
+	}
+	files := make([]schema.RunWorkflowRequestFile, 0, len(c.Files))
+	for _, f := range c.Files {
+		files = append(files, schema.RunWorkflowRequestFile{
+			Type:           f.Type,
+			TransferMethod: f.TransferMethod,
+			URL:            f.URL,
+			UploadFileID:   f.UploadFileID,
+		})
+	}
+
+	start := time.Now()
+	resp, err := runner.Run(ctx, schema.RunWorkflowRequest{
+		Inputs:       inputs,
+		ResponseMode: v1.BlockingMode,
+		User:         user,
+		Files:        files,
+	})
+	res.Elapsed = time.Since(start)
+	if err != nil {
+		return err
+	}
+	res.RunID, res.Status = resp.WorkflowRunID, resp.Data.Status
+	res.Failures = evaluate(c.Expect, resp.Data)
+	return nil
+}
+
+// evaluate returns the expectations not met by a run.
+func evaluate(expect Expectation, data schema.RunWorkflowResponseData) []Failure {
+	status := expect.Status
+	if status == "" {
+		status = schema.WorkflowStatusSucceeded
+	}
+	if data.Status != status {
+		f := Failure{Matcher: "status", Expected: status, Actual: data.Status}
+		if data.Error != "" {
+			f.Actual += " (" + data.Error + ")"
+		}
+		return []Failure{f}
+	}
+
+	var failures []Failure
+	paths := make([]string, 0, len(expect.Outputs))
+	for path := range expect.Outputs {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		value, found := lookup(data.Outputs, path)
+		failures = append(failures, expect.Outputs[path].check(path, value, found)...)
+	}
+	return failures
+}
@@ -0,0 +1,106 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package workflowtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeeaiclub/dify-go/schema"
+)
+
+type fakeRunner func(inputs map[string]any) (map[string]any, error)
+
+func (f fakeRunner) Run(_ context.Context, req schema.RunWorkflowRequest) (schema.RunWorkflowResponse, error) {
+	var inputs map[string]any
+	if err := json.Unmarshal(req.Inputs, &inputs); err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
+	outputs, err := f(inputs)
+	if err != nil {
+		return schema.RunWorkflowResponse{}, err
+	}
+	return schema.RunWorkflowResponse{
+		WorkflowRunID: "run-" + inputs["name"].(string),
+		Data:          schema.RunWorkflowResponseData{Status: schema.WorkflowStatusSucceeded, Outputs: outputs},
+	}, nil
+}
+
+const suiteYAML = `
+name: greeter
+cases:
+  - name: passes
+    inputs: {name: Bob}
+    expect:
+      outputs:
+        greeting: Hello Bob
+        meta: {exact: {lang: en, tags: [a, b]}}
+        meta.tags[1]: b
+        summary: {contains: Bob, regex: "^Hello"}
+        score: {number: 0.8, tolerance: 0.05}
+  - name: fails
+    inputs: {name: Alice}
+    expect:
+      outputs:
+        greeting: Hello Bob
+        score: {number: 0.5}
+        missing: x
+  - name: errors
+    inputs: {name: boom}
+`
+
+func TestRun(t *testing.T) {
+	suite, err := ParseSuite([]byte(suiteYAML))
+	require.NoError(t, err)
+
+	runner := fakeRunner(func(inputs map[string]any) (map[string]any, error) {
+		name := inputs["name"].(string)
+		if name == "boom" {
+			return nil, errors.New("service unavailable")
+		}
+		return map[string]any{
+			"greeting": "Hello " + name,
+			"summary":  "Hello " + name + ", welcome",
+			"score":    0.82,
+			"meta":     map[string]any{"lang": "en", "tags": []any{"a", "b"}},
+		}, nil
+	})
+	report := Run(t.Context(), runner, suite, Options{Concurrency: 2})
+	require.Len(t, report.Results, 3)
+
+	assert.True(t, report.Results[0].Passed(), report.Results[0].Failures)
+
+	failures := report.Results[1].Failures
+	require.Len(t, failures, 3)
+	assert.Equal(t, "greeting", failures[0].Output)
+	assert.Equal(t, `"Hello Alice"`, failures[0].Actual)
+	assert.Equal(t, "missing", failures[1].Output)
+	assert.Equal(t, "score", failures[2].Output)
+
+	assert.Equal(t, "service unavailable", report.Results[2].Err)
+	passed, failed, errored := report.Counts()
+	assert.Equal(t, []int{1, 1, 1}, []int{passed, failed, errored})
+
+	var junit bytes.Buffer
+	require.NoError(t, WriteJUnit(&junit, report))
+	assert.Contains(t, junit.String(), `<testsuite name="greeter" tests="3" failures="1" errors="1"`)
+}
+
+func TestParseSuite(t *testing.T) {
+	_, err := ParseSuite([]byte("cases:\n  - name: a\n    expect:\n      outputs:\n        x: {equals: 1}\n"))
+	assert.ErrorContains(t, err, `unknown matcher "equals"`)
+
+	_, err = ParseSuite([]byte("cases:\n  - name: a\n  - name: a\n"))
+	assert.ErrorContains(t, err, "duplicate case")
+}
+
+func TestLineDiff(t *testing.T) {
+	assert.Equal(t, "  a\n- b\n+ B\n  c\n", lineDiff("a\nb\nc", "a\nB\nc"))
+}
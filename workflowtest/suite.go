@@ -0,0 +1,140 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package workflowtest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a list of cases run against one workflow.
+type Suite struct {
+	// Name identifies the suite in reports. Default: the file name without extension
+	Name string `yaml:"name"`
+	// User is the end user sent with every run. Default: workflowtest
+	User  string `yaml:"user"`
+	Cases []Case `yaml:"cases"`
+}
+
+// Case is a workflow run and the result expected from it.
+type Case struct {
+	Name   string         `yaml:"name"`
+	Inputs map[string]any `yaml:"inputs"`
+	Files  []File         `yaml:"files"`
+	Expect Expectation    `yaml:"expect"`
+}
+
+// File is a file input of a case.
+type File struct {
+	Type           string `yaml:"type"`
+	TransferMethod string `yaml:"transfer_method"`
+	URL            string `yaml:"url"`
+	UploadFileID   string `yaml:"upload_file_id"`
+}
+
+// Expectation is the expected result of a case.
+type Expectation struct {
+	// Status is the expected run status. Default: succeeded
+	Status string `yaml:"status"`
+	// Outputs maps paths into the outputs, such as "result.items[0].name", to their matcher.
+	Outputs map[string]Matcher `yaml:"outputs"`
+}
+
+// Matcher checks an output value. Every set field must match.
+type Matcher struct {
+	// Exact requires the value to equal Exact once both are converted to JSON values.
+	Exact any `yaml:"exact"`
+	// Contains requires a string value to contain Contains, or a list value to have an element equal to it.
+	Contains any `yaml:"contains"`
+	// Regex requires the value, as a string or JSON, to match the regular expression.
+	Regex string `yaml:"regex"`
+	// Number requires a numeric value within Tolerance of Number.
+	Number    *float64 `yaml:"number"`
+	Tolerance float64  `yaml:"tolerance"`
+
+	hasExact bool
+	regex    *regexp.Regexp
+}
+
+// matcherKeys are the keys of a mapping matcher.
+var matcherKeys = map[string]bool{"exact": true, "contains": true, "regex": true, "number": true, "tolerance": true}
+
+// UnmarshalYAML decodes a matcher. A scalar or a list is an exact matcher.
+func (m *Matcher) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		m.hasExact = true
+		return node.Decode(&m.Exact)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; !matcherKeys[key] {
+			return fmt.Errorf("line %d: unknown matcher %q, use exact to compare objects", node.Content[i].Line, key)
+		}
+	}
+	type plain Matcher
+	if err := node.Decode((*plain)(m)); err != nil {
+		return err
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == "exact" {
+			m.hasExact = true
+		}
+	}
+	if m.Regex != "" {
+		re, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		m.regex = re
+	}
+	return nil
+}
+
+// LoadSuite reads a suite file.
+func LoadSuite(name string) (*Suite, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	suite, err := ParseSuite(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if suite.Name == "" {
+		base := filepath.Base(name)
+		suite.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return suite, nil
+}
+
+// ParseSuite parses a YAML suite. Case names must be unique.
+func ParseSuite(data []byte) (*Suite, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var suite Suite
+	if err := dec.Decode(&suite); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(suite.Cases))
+	for i, c := range suite.Cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("case %d has no name", i+1)
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("duplicate case %q", c.Name)
+		}
+		names[c.Name] = true
+		for path := range c.Expect.Outputs {
+			if _, err := parsePath(path); err != nil {
+				return nil, fmt.Errorf("case %q: %w", c.Name, err)
+			}
+		}
+	}
+	return &suite, nil
+}
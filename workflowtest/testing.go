@@ -0,0 +1,33 @@
+// Copyright The yeeaiclub Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package workflowtest
+
+import (
+	"testing"
+)
+
+// Test runs suite and reports every case as a subtest of t:
+//
+//	func TestSummarizer(t *testing.T) {
+//		suite, err := workflowtest.LoadSuite("testdata/summarizer.yml")
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		workflowtest.Test(t, v1.NewWorkflowService(baseURL, apiKey), suite, workflowtest.Options{})
+//	}
+func Test(t *testing.T, runner Runner, suite *Suite, opts Options) *Report {
+	t.Helper()
+	report := Run(t.Context(), runner, suite, opts)
+	for _, res := range report.Results {
+		t.Run(res.Name, func(t *testing.T) {
+			if res.Err != "" {
+				t.Fatalf("run failed: %s", res.Err)
+			}
+			for _, f := range res.Failures {
+				t.Error(f)
+			}
+		})
+	}
+	return report
+}